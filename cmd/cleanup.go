@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/JCSong-89/trpg-rag-game/internal/db"
+	"github.com/JCSong-89/trpg-rag-game/pkg/types"
+	"log"
+	"os"
+	"strings"
+)
+
+// runCleanup 은 데이터셋 하나만 지웁니다. -confirm 에 데이터셋 이름을 주지 않으면 이름을 직접 입력해야 진행합니다.
+func runCleanup(ctx context.Context, configData types.Config, confirm string) {
+	dataset := configData.Db.Dataset
+	if confirm != dataset {
+		fmt.Printf("데이터셋 '%s'의 그래프와 벡터를 모두 삭제합니다. 계속하려면 데이터셋 이름을 입력하세요: ", dataset)
+		scanner := bufio.NewScanner(os.Stdin)
+		if !scanner.Scan() || strings.TrimSpace(scanner.Text()) != dataset {
+			log.Println("데이터셋 이름이 일치하지 않아 삭제를 취소했습니다.")
+			return
+		}
+	}
+
+	graphStore, vectorStore := openStores(ctx, configData)
+	defer graphStore.Close(ctx)
+	defer vectorStore.Close()
+
+	if err := db.Cleanup(ctx, graphStore, vectorStore, dataset); err != nil {
+		log.Fatalf("데이터셋 '%s' 삭제 실패: %v", dataset, err)
+	}
+	if path := manifestPath(configData); path != "" {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Printf("경고: 적재 기록 삭제 실패: %v", err)
+		}
+	}
+	log.Printf("데이터셋 '%s'을 삭제했습니다.", dataset)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"github.com/JCSong-89/trpg-rag-game/internal/llm"
+	"github.com/JCSong-89/trpg-rag-game/internal/service"
+	"github.com/JCSong-89/trpg-rag-game/pkg/ontology"
+	"github.com/JCSong-89/trpg-rag-game/pkg/types"
+	"github.com/JCSong-89/trpg-rag-game/pkg/utils"
+	"log"
+	"path/filepath"
+	"time"
+)
+
+// runIngest 는 paths 의 문서에서 엔티티와 관계를 추출해 데이터셋에 병합합니다. 기존 데이터는 지우지 않습니다.
+// 같은 내용으로 이미 적재한 문서는 force 가 아니면 건너뜁니다. paths 는 main 에서 하나 이상인지 확인합니다.
+func runIngest(ctx context.Context, configData types.Config, paths []string, force bool) {
+	documents, err := utils.LoadDocuments(paths)
+	if err != nil {
+		log.Fatalf("문서 읽기 실패: %v", err)
+	}
+	if len(documents) == 0 {
+		log.Println("적재할 문서가 없습니다.")
+		return
+	}
+
+	var schema *ontology.Ontology
+	if configData.Ingest.OntologyPath != "" {
+		schema, err = ontology.Load(configData.Ingest.OntologyPath)
+		if err != nil {
+			log.Fatalf("온톨로지 읽기 실패: %v", err)
+		}
+		log.Printf("온톨로지 '%s': 라벨 %d개, 관계 타입 %d개", configData.Ingest.OntologyPath, len(schema.Labels), len(schema.Relations))
+	}
+
+	provider := newProvider(configData)
+	embedder, closeEmbedder := newEmbedder(configData)
+	defer closeEmbedder()
+	graphStore, vectorStore := openStores(ctx, configData)
+	defer graphStore.Close(ctx)
+	defer vectorStore.Close()
+
+	collectionName := configData.Db.Dataset
+	if err := vectorStore.CreateCollection(ctx, collectionName, embedder.Dimension()); err != nil {
+		log.Fatalf("벡터 컬렉션 생성 실패: %v", err)
+	}
+
+	manifest, err := service.OpenIngestManifest(manifestPath(configData))
+	if err != nil {
+		log.Fatalf("적재 기록 열기 실패: %v", err)
+	}
+
+	ingestUsage := llm.NewUsageTracker("적재", configData.LLM.Prices)
+	ingestProvider := llm.NewMeteredProvider(provider, ingestUsage)
+
+	ingested, skipped, failed := 0, 0, 0
+	for _, document := range documents {
+		if !force && manifest.Unchanged(document) {
+			log.Printf("문서 '%s'은 이미 같은 내용으로 적재되어 건너뜁니다.", document.ID)
+			skipped++
+			continue
+		}
+
+		parsedData, err := service.IngestDocument(ctx, ingestProvider, graphStore, vectorStore, embedder, collectionName, document, configData.Ingest, schema)
+		if err != nil {
+			log.Printf("에러: %v", err)
+			failed++
+			continue
+		}
+		ingested++
+
+		// 저장까지 모두 성공한 문서만 기록해야 다음 실행에서 실패한 문서를 다시 적재합니다.
+		err = manifest.Record(service.IngestRecord{
+			Dataset:    collectionName,
+			DocumentID: document.ID,
+			Source:     document.Source,
+			Hash:       document.Hash,
+			Entities:   len(parsedData.Entities),
+			Relations:  len(parsedData.Relations),
+			IngestedAt: time.Now(),
+		})
+		if err != nil {
+			log.Printf("경고: %v", err)
+		}
+	}
+
+	log.Printf("문서 적재 완료: 적재 %d개, 건너뜀 %d개, 실패 %d개", ingested, skipped, failed)
+	log.Printf("토큰 사용량 %s", ingestUsage.Report())
+}
+
+// manifestPath 는 데이터셋의 적재 기록 파일 경로입니다. 기록을 끈 경우 빈 문자열입니다.
+func manifestPath(configData types.Config) string {
+	if configData.Ingest.ManifestDir == "" {
+		return ""
+	}
+	return filepath.Join(configData.Ingest.ManifestDir, configData.Db.Dataset+".jsonl")
+}
@@ -2,23 +2,32 @@ package main
 
 import (
 	"context"
-	"encoding/json"
+	"flag"
 	"fmt"
 	"github.com/JCSong-89/trpg-rag-game/internal/config"
 	"github.com/JCSong-89/trpg-rag-game/internal/db"
 	"github.com/JCSong-89/trpg-rag-game/internal/llm"
-	"github.com/JCSong-89/trpg-rag-game/internal/prompt"
-	"github.com/JCSong-89/trpg-rag-game/internal/service"
 	"github.com/JCSong-89/trpg-rag-game/pkg/types"
-	"github.com/JCSong-89/trpg-rag-game/pkg/utils"
 	"github.com/joho/godotenv"
-	"github.com/qdrant/go-client/qdrant"
 	"log"
+	"os"
+	"regexp"
 )
 
-func main() {
-	userQuery := "LA FC 회장의 직접적인 설득 외에, 손흥민의 이번 이적 결정에 영향을 미친 가장 중요하고 거시적인 외부 요인은 무엇이었나요?"
+// datasetNamePattern 은 데이터셋 이름이 벡터 컬렉션 이름과 파일 이름으로도 안전하게 쓰이도록 제한합니다.
+var datasetNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+const usage = `사용법: trpg-rag-game <command> [flags] [파일 또는 디렉터리...]
+
+명령:
+  ingest   파일(txt, md, html, jsonl)이나 디렉터리의 문서를 추출해 데이터셋에 적재합니다 (기존 데이터에 병합)
+  query    데이터셋에 대화형으로 질문합니다
+  cleanup  데이터셋 하나를 삭제합니다 (확인 필요)
 
+flags:
+`
+
+func main() {
 	err := godotenv.Load()
 	if err != nil {
 		log.Fatalf(".env 파일을 로드하는 데 실패했습니다: %v", err)
@@ -27,109 +36,90 @@ func main() {
 	ctx := context.Background()
 	configData := config.LoadConfig()
 
-	neo4jDriver := db.NewNeo4jDriver(configData)
-	defer neo4jDriver.Close(ctx)
-
-	quadrantCollectionClient, pointsClient, grpcConn := db.NewQuadrantClient(configData)
-	defer grpcConn.Close()
-
-	/*TODO: 데이터 적재 시작 후에 삭제*/
-	collectionName := "football_news"
-	db.Cleanup(ctx, neo4jDriver, quadrantCollectionClient, collectionName)
-
-	_, err = quadrantCollectionClient.Create(ctx, &qdrant.CreateCollection{
-		CollectionName: collectionName,
-		VectorsConfig: &qdrant.VectorsConfig{Config: &qdrant.VectorsConfig_Params{
-			Params: &qdrant.VectorParams{Size: 1024, Distance: qdrant.Distance_Cosine},
-		}},
-	})
-
-	responseText, err := llm.GenerateContentWithHTTP(ctx, prompt.SystemPromt)
-	if err != nil {
-		log.Fatalf("API 호출 중 에러 발생: %v", err)
+	flags := flag.NewFlagSet("trpg-rag-game", flag.ExitOnError)
+	dataset := flags.String("dataset", configData.Db.Dataset, "대상 데이터셋(캠페인) 이름")
+	confirm := flags.String("confirm", "", "cleanup 확인용 데이터셋 이름. 대상 데이터셋과 같아야 묻지 않고 삭제합니다")
+	force := flags.Bool("force", false, "ingest 에서 이미 같은 내용으로 적재한 문서도 다시 적재합니다")
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), usage)
+		flags.PrintDefaults()
 	}
 
-	jsonData, err := utils.ExtractJSONFromString(responseText)
-	if err != nil {
-		log.Fatalf("API 호출 중 에러 발생: %v", err)
+	if len(os.Args) < 2 {
+		flags.Usage()
+		os.Exit(2)
 	}
+	command := os.Args[1]
+	flags.Parse(os.Args[2:])
 
-	entities, relations, err := service.ParseAndRefineResponse(jsonData)
-	if err != nil {
-		log.Fatalf("LLM 응답 정제 실패: %v", err)
+	if !datasetNamePattern.MatchString(*dataset) {
+		log.Fatalf("데이터셋 이름에는 영문, 숫자, '_', '-' 만 쓸 수 있습니다: %q", *dataset)
 	}
-
-	/* TODO: 임베딩 과정과 릴레이션 생성은 고루틴으로 돌리는게 좋을 듯 */
-	service.ProcessAndStoreEntities(ctx, neo4jDriver, pointsClient, collectionName, entities)
-	service.InsertRelations(ctx, neo4jDriver, relations)
-	fmt.Println(responseText)
-
-	log.Println("경로 1: LLM 키워드 기반 엔티티 추출 시작...")
-	keywordPrompt := fmt.Sprintf(prompt.EntityExtractionPromptTemplate, userQuery)
-	keyword, err := llm.GenerateContentWithHTTP(ctx, keywordPrompt)
-	if err != nil {
-		log.Fatalf("Gemini 엔티티 추출 API 호출 실패: %v", err)
+	configData.Db.Dataset = *dataset
+
+	switch command {
+	case "ingest":
+		if flags.NArg() == 0 {
+			fmt.Fprintln(flags.Output(), "ingest 에는 적재할 파일이나 디렉터리를 하나 이상 지정해야 합니다.")
+			flags.Usage()
+			os.Exit(2)
+		}
+		runIngest(ctx, configData, flags.Args(), *force)
+	case "query":
+		runQuery(ctx, configData)
+	case "cleanup":
+		runCleanup(ctx, configData, *confirm)
+	default:
+		flags.Usage()
+		os.Exit(2)
 	}
+}
 
-	jsonString, err := utils.ExtractJSONFromString(keyword)
+func newProvider(configData types.Config) llm.Provider {
+	provider, err := llm.NewProvider(configData.LLM)
 	if err != nil {
-		log.Fatalf("응답에서 JSON 추출 실패: %v", err)
+		log.Fatalf("LLM 프로바이더 생성 실패: %v", err)
 	}
-	var keywordEntityNames []string
-	if err := json.Unmarshal([]byte(jsonString), &keywordEntityNames); err != nil {
-		log.Fatalf("JSON 배열 파싱 실패: %v", err)
+	if configData.LLM.Cache.Enabled {
+		provider, err = llm.NewCachedProvider(provider, configData.LLM.Model, configData.LLM.Cache)
+		if err != nil {
+			log.Fatalf("LLM 응답 캐시 초기화 실패: %v", err)
+		}
 	}
-	log.Printf("키워드 기반 추출 결과: %v", keywordEntityNames)
+	return provider
+}
 
-	log.Println("\n경로 2: Qdrant 의미 기반 엔티티 검색 시작...")
-	vectorEntityNames, err := service.FindTopKSimilarEntities(ctx, pointsClient, collectionName, userQuery, 3)
+// newEmbedder 는 임베더와, 종료할 때 캐시 통계를 남기고 캐시 파일을 닫는 함수를 돌려줍니다.
+func newEmbedder(configData types.Config) (llm.Embedder, func()) {
+	embedder, err := llm.NewEmbedder(configData.Embedding)
 	if err != nil {
-		log.Printf("경고: Qdrant 의미 검색 실패: %v", err)
-	}
-
-	combinedEntities := make(map[string]bool)
-	for _, name := range keywordEntityNames {
-		combinedEntities[name] = true
+		log.Fatalf("임베딩 프로바이더 생성 실패: %v", err)
 	}
-	for _, name := range vectorEntityNames {
-		combinedEntities[name] = true
+	if configData.Embedding.CachePath == "" {
+		return embedder, func() {}
 	}
 
-	var finalEntityNames []string
-	for name := range combinedEntities {
-		finalEntityNames = append(finalEntityNames, name)
+	cachedEmbedder, err := llm.NewCachedEmbedder(embedder, configData.Embedding.CachePath)
+	if err != nil {
+		log.Fatalf("임베딩 캐시 초기화 실패: %v", err)
 	}
-	log.Printf("\n통합된 최종 탐색 시작 엔티티: %v", finalEntityNames)
-
-	var allSubgraphs []*types.Subgraph
-	for _, entityName := range finalEntityNames {
-		log.Printf("'%s' 엔티티에 대한 서브그래프 생성 중...", entityName)
-
-		oneHopSubgraph, err := service.GetOneHopSubgraph(ctx, neo4jDriver, entityName)
-		if err != nil {
-			log.Printf("경고: '%s'의 OneHop 서브그래프 생성 실패: %v", entityName, err)
-		}
-
-		multiHopSubgraph, err := service.GetMultiHopSubgraph(ctx, neo4jDriver, entityName, 10)
-		if err != nil {
-			log.Printf("경고: '%s'의 MultiHop 서브그래프 생성 실패: %v", entityName, err)
-		}
-
-		importanceBasedSubgraph, err := service.GetImportanceBasedSubgraph(ctx, neo4jDriver, entityName, 5)
-		if err != nil {
-			log.Printf("경고: '%s'의 importBased 서브그래프 생성 실패: %v", entityName, err)
-		}
-
-		allSubgraphs = append(allSubgraphs, oneHopSubgraph, multiHopSubgraph, importanceBasedSubgraph)
+	return cachedEmbedder, func() {
+		stats := cachedEmbedder.Stats()
+		log.Printf("임베딩 캐시: 적중 %d회, 미스 %d회, 저장 %d개", stats.Hits, stats.Misses, stats.Entries)
+		cachedEmbedder.Close()
 	}
+}
 
-	fusedSubgraph := service.FuseSubgraph(ctx, allSubgraphs, userQuery)
-	contextString := utils.SubgraphToString(fusedSubgraph)
-	finalPrompt := fmt.Sprintf(prompt.FinalPromptTemplate, contextString, userQuery)
-	answer, err := llm.GenerateContentWithHTTP(ctx, finalPrompt)
+func openStores(ctx context.Context, configData types.Config) (db.GraphStore, db.VectorStore) {
+	graphStore, err := db.NewGraphStore(configData)
 	if err != nil {
-		log.Fatal("LLM 최종 답변 생성 실패: %w", err)
+		log.Fatalf("그래프 저장소 생성 실패: %v", err)
 	}
 
-	fmt.Println("최종 답변:", answer)
-}
\ No newline at end of file
+	vectorStore, err := db.NewVectorStore(configData)
+	if err != nil {
+		graphStore.Close(ctx)
+		log.Fatalf("벡터 저장소 생성 실패: %v", err)
+	}
+	return graphStore, vectorStore
+}
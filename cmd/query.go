@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"github.com/JCSong-89/trpg-rag-game/internal/db"
+	"github.com/JCSong-89/trpg-rag-game/internal/llm"
+	"github.com/JCSong-89/trpg-rag-game/internal/prompt"
+	"github.com/JCSong-89/trpg-rag-game/internal/service"
+	"github.com/JCSong-89/trpg-rag-game/pkg/types"
+	"github.com/JCSong-89/trpg-rag-game/pkg/utils"
+	"log"
+	"os"
+	"strings"
+)
+
+const exampleQuery = "LA FC 회장의 직접적인 설득 외에, 손흥민의 이번 이적 결정에 영향을 미친 가장 중요하고 거시적인 외부 요인은 무엇이었나요?"
+
+// 컨텍스트에 붙이는 출처 원문의 개수와 청크 하나당 길이 상한입니다.
+const (
+	contextSourceChunks = 5
+	contextExcerptRunes = 600
+)
+
+// runQuery 는 이미 적재된 데이터셋에 대해 대화형으로 질문을 받습니다. 저장소에는 읽기만 합니다.
+func runQuery(ctx context.Context, configData types.Config) {
+	provider := newProvider(configData)
+	embedder, closeEmbedder := newEmbedder(configData)
+	defer closeEmbedder()
+	graphStore, vectorStore := openStores(ctx, configData)
+	defer graphStore.Close(ctx)
+	defer vectorStore.Close()
+
+	collectionName := configData.Db.Dataset
+	count, err := vectorStore.Count(ctx, collectionName, nil)
+	if err != nil {
+		log.Fatalf("데이터셋 '%s'을 찾을 수 없습니다. 먼저 ingest 를 실행하세요: %v", collectionName, err)
+	}
+	log.Printf("데이터셋 '%s' (벡터 %d개)에 질의합니다.", collectionName, count)
+
+	queryUsage := llm.NewUsageTracker("질의 세션", configData.LLM.Prices)
+	queryProvider := llm.NewMeteredProvider(provider, queryUsage)
+	conversation := llm.NewConversation(queryProvider, configData.Conversation)
+	pipeline := &queryPipeline{
+		provider:       queryProvider,
+		graph:          graphStore,
+		vectors:        vectorStore,
+		embedder:       embedder,
+		collectionName: collectionName,
+		tools:          service.NewGameMasterTools(graphStore, vectorStore, embedder, collectionName),
+		agentMaxSteps:  configData.Agent.MaxSteps,
+	}
+
+	fmt.Printf("질문을 입력하세요. 빈 줄이나 exit 를 입력하면 종료합니다.\n예: %s\n", exampleQuery)
+	scanner := bufio.NewScanner(os.Stdin)
+	questionCount := 0
+	for {
+		fmt.Print("질문> ")
+		if !scanner.Scan() {
+			break
+		}
+		userQuery := strings.TrimSpace(scanner.Text())
+		if userQuery == "" || userQuery == "exit" {
+			break
+		}
+		questionCount++
+		usageBefore := queryUsage.Report()
+
+		searchQuery, err := conversation.ResolveFollowUp(ctx, userQuery)
+		if err != nil {
+			log.Printf("경고: %v", err)
+			searchQuery = userQuery
+		}
+		if searchQuery != userQuery {
+			log.Printf("후속 질문 재작성: %q -> %q", userQuery, searchQuery)
+		}
+
+		answer, err := pipeline.answer(ctx, searchQuery, conversation.History())
+		questionReport := queryUsage.Report().Since(usageBefore, fmt.Sprintf("질문 %d", questionCount))
+		fmt.Println("토큰 사용량:", questionReport)
+		log.Printf("토큰 사용량 %s", questionReport)
+		if err != nil {
+			log.Printf("질의 처리 실패: %v", err)
+			continue
+		}
+
+		conversation.AddExchange(userQuery, answer)
+		if err := conversation.Compact(ctx); err != nil {
+			log.Printf("경고: %v", err)
+		}
+	}
+
+	// 질문마다 사용량을 출력하므로, 종료할 때는 세션 전체 합계를 한 번 더 남깁니다.
+	queryReport := queryUsage.Report()
+	fmt.Println("세션 토큰 사용량:", queryReport)
+	log.Printf("세션 토큰 사용량 %s", queryReport)
+}
+
+// queryPipeline 은 질문 하나를 답변으로 바꾸는 데 필요한 클라이언트와 설정을 묶습니다.
+type queryPipeline struct {
+	provider       llm.Provider
+	graph          db.GraphStore
+	vectors        db.VectorStore
+	embedder       llm.Embedder
+	collectionName string
+	tools          *llm.ToolRegistry
+	agentMaxSteps  int
+}
+
+// answer 는 엔티티 추출, 벡터 검색, 서브그래프 융합으로 컨텍스트를 만든 뒤 최종 답변을 돌려줍니다.
+// history 는 이전 대화로, 최종 답변 생성에만 전달됩니다.
+func (p *queryPipeline) answer(ctx context.Context, userQuery string, history []llm.Message) (string, error) {
+	log.Println("경로 1: LLM 키워드 기반 엔티티 추출 시작...")
+	keywordPrompt := fmt.Sprintf(prompt.EntityExtractionPromptTemplate, userQuery)
+	var keywordEntityNames []string
+	if _, err := llm.GenerateJSON(ctx, p.provider, keywordPrompt, &keywordEntityNames, llm.WithStage(llm.StageQueryNER)); err != nil {
+		return "", fmt.Errorf("%s 엔티티 추출 API 호출 실패: %w", p.provider.Name(), err)
+	}
+	log.Printf("키워드 기반 추출 결과: %v", keywordEntityNames)
+
+	log.Println("\n경로 2: 벡터 의미 기반 엔티티 검색 시작...")
+	vectorEntityNames, err := service.FindTopKSimilarEntities(ctx, p.vectors, p.embedder, p.collectionName, userQuery, 3)
+	if err != nil {
+		log.Printf("경고: 벡터 의미 검색 실패: %v", err)
+	}
+
+	combinedEntities := make(map[string]bool)
+	for _, name := range keywordEntityNames {
+		combinedEntities[name] = true
+	}
+	for _, name := range vectorEntityNames {
+		combinedEntities[name] = true
+	}
+
+	var finalEntityNames []string
+	for name := range combinedEntities {
+		finalEntityNames = append(finalEntityNames, name)
+	}
+	log.Printf("\n통합된 최종 탐색 시작 엔티티: %v", finalEntityNames)
+
+	var allSubgraphs []*types.Subgraph
+	for _, entityName := range finalEntityNames {
+		log.Printf("'%s' 엔티티에 대한 서브그래프 생성 중...", entityName)
+
+		oneHopSubgraph, err := service.GetOneHopSubgraph(ctx, p.graph, entityName)
+		if err != nil {
+			log.Printf("경고: '%s'의 OneHop 서브그래프 생성 실패: %v", entityName, err)
+		}
+
+		multiHopSubgraph, err := service.GetMultiHopSubgraph(ctx, p.graph, entityName, 10)
+		if err != nil {
+			log.Printf("경고: '%s'의 MultiHop 서브그래프 생성 실패: %v", entityName, err)
+		}
+
+		importanceBasedSubgraph, err := service.GetImportanceBasedSubgraph(ctx, p.graph, entityName, 5)
+		if err != nil {
+			log.Printf("경고: '%s'의 importBased 서브그래프 생성 실패: %v", entityName, err)
+		}
+
+		allSubgraphs = append(allSubgraphs, oneHopSubgraph, multiHopSubgraph, importanceBasedSubgraph)
+	}
+
+	fusedSubgraph := service.FuseSubgraph(ctx, p.provider, allSubgraphs, userQuery)
+	contextString := utils.SubgraphToString(fusedSubgraph)
+	sourceChunks, err := service.CollectSourceChunks(ctx, p.graph, fusedSubgraph, contextSourceChunks)
+	if err != nil {
+		log.Printf("경고: 출처 청크 조회 실패: %v", err)
+	}
+	if len(sourceChunks) > 0 {
+		contextString += "\n" + utils.SourceChunksToString(sourceChunks, contextExcerptRunes)
+	}
+	if p.agentMaxSteps > 0 {
+		return p.answerWithTools(ctx, contextString, userQuery, history)
+	}
+
+	finalPrompt := fmt.Sprintf(prompt.FinalPromptTemplate, contextString, userQuery)
+	answerStream, err := llm.GenerateStream(ctx, p.provider, finalPrompt, llm.WithStage(llm.StageFinalAnswer), llm.WithHistory(history), llm.WithNoCache())
+	if err != nil {
+		return "", fmt.Errorf("LLM 최종 답변 생성 실패: %w", err)
+	}
+
+	fmt.Print("최종 답변: ")
+	var answer strings.Builder
+	for chunk := range answerStream {
+		if chunk.Err != nil {
+			fmt.Println()
+			return "", fmt.Errorf("LLM 최종 답변 스트리밍 실패: %w", chunk.Err)
+		}
+		fmt.Print(chunk.Text)
+		answer.WriteString(chunk.Text)
+	}
+	fmt.Println()
+	return answer.String(), nil
+}
+
+// answerWithTools 는 게임 마스터 에이전트가 필요한 만큼 도구를 호출한 뒤 답하게 하고, 도구 호출 기록을 함께 출력합니다.
+func (p *queryPipeline) answerWithTools(ctx context.Context, contextString string, userQuery string, history []llm.Message) (string, error) {
+	agentPrompt := fmt.Sprintf(prompt.GameMasterPromptTemplate, contextString, userQuery)
+	result, err := llm.RunAgent(ctx, p.provider, p.tools, agentPrompt, p.agentMaxSteps,
+		llm.WithStage(llm.StageFinalAnswer), llm.WithHistory(history), llm.WithNoCache())
+	if err != nil {
+		return "", fmt.Errorf("게임 마스터 에이전트 실행 실패: %w", err)
+	}
+
+	for _, invocation := range result.Transcript {
+		status := "성공"
+		if invocation.Err != "" {
+			status = "실패: " + invocation.Err
+		}
+		fmt.Printf("[도구 %d단계] %s(%s) %s\n", invocation.Step, invocation.Name, invocation.Arguments, status)
+	}
+	fmt.Println("최종 답변:", result.Text)
+	return result.Text, nil
+}
@@ -1,8 +1,13 @@
 package config
 
 import (
+	"github.com/JCSong-89/trpg-rag-game/pkg/chunker"
 	"github.com/JCSong-89/trpg-rag-game/pkg/types"
+	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 func LoadConfig() types.Config {
@@ -13,14 +18,317 @@ func LoadConfig() types.Config {
 	serverPort := os.Getenv("SERVER_PORT")
 
 	dbConfig := types.DbConfig{
-		Neo4jUrl:    neo4jURI,
-		Neo4jUser:   neo4jUser,
-		Neo4jPass:   neo4jPass,
-		QuadrantUrI: quadrantURI,
+		Neo4jUrl:       neo4jURI,
+		Neo4jUser:      neo4jUser,
+		Neo4jPass:      neo4jPass,
+		QuadrantUrI:    quadrantURI,
+		GraphStore:     os.Getenv("GRAPH_STORE"),
+		GraphStoreDir:  getEnvString("GRAPH_STORE_DIR", ".graph"),
+		VectorStore:    os.Getenv("VECTOR_STORE"),
+		VectorStoreDir: getEnvString("VECTOR_STORE_DIR", ".vectors"),
+		Dataset:        getEnvString("DATASET", "football_news"),
 	}
 
 	return types.Config{
 		ServerPort: serverPort,
 		Db:         dbConfig,
+		LLM:        loadLLMConfig(),
+		Embedding:  loadEmbeddingConfig(),
+		Ingest: types.IngestConfig{
+			BatchSize:      getEnvInt("INGEST_BATCH_SIZE", 32),
+			Concurrency:    getEnvInt("INGEST_CONCURRENCY", 4),
+			ConflictPolicy: loadConflictPolicy(),
+			ManifestDir:    getEnvString("INGEST_MANIFEST_DIR", ".ingest"),
+			ChunkStrategy:  loadChunkStrategy(),
+			ChunkTokens:    getEnvInt("INGEST_CHUNK_TOKENS", chunker.DefaultMaxTokens),
+			ChunkOverlap:   getEnvInt("INGEST_CHUNK_OVERLAP", chunker.DefaultOverlapTokens),
+			Resolution: types.ResolutionConfig{
+				Enabled:         os.Getenv("INGEST_RESOLVE_ENTITIES") != "false",
+				Similarity:      getEnvFloat("INGEST_RESOLVE_SIMILARITY", 0.92),
+				AliasSimilarity: getEnvFloat("INGEST_RESOLVE_ALIAS_SIMILARITY", 0.75),
+				CandidateTopK:   getEnvInt("INGEST_RESOLVE_TOP_K", 3),
+			},
+			OntologyPath: os.Getenv("INGEST_ONTOLOGY_PATH"),
+		},
+		Conversation: types.ConversationConfig{
+			TokenBudget:  getEnvInt("CONVERSATION_TOKEN_BUDGET", 4000),
+			KeepMessages: getEnvInt("CONVERSATION_KEEP_MESSAGES", 4),
+		},
+		Agent: types.AgentConfig{
+			MaxSteps: getEnvInt("AGENT_MAX_STEPS", 6),
+		},
 	}
-}
\ No newline at end of file
+}
+
+func loadLLMConfig() types.LLMConfig {
+	provider := os.Getenv("LLM_PROVIDER")
+	if provider == "" {
+		provider = "gemini"
+	}
+
+	apiKey := os.Getenv("LLM_API_KEY")
+	if apiKey == "" {
+		apiKey = defaultLLMAPIKey(provider)
+	}
+	baseURL := os.Getenv("LLM_BASE_URL")
+	routes := parseLLMRoutes(os.Getenv("LLM_ROUTES"))
+
+	return types.LLMConfig{
+		Provider:       provider,
+		Model:          os.Getenv("LLM_MODEL"),
+		BaseURL:        baseURL,
+		APIKey:         apiKey,
+		Temperature:    getEnvFloatPtr("LLM_TEMPERATURE"),
+		TopP:           getEnvFloatPtr("LLM_TOP_P"),
+		MaxTokens:      getEnvInt("LLM_MAX_TOKENS", 0),
+		SystemPrompt:   os.Getenv("LLM_SYSTEM_PROMPT"),
+		SafetySettings: parseGeminiSafetySettings(os.Getenv("GEMINI_SAFETY_SETTINGS")),
+		ScriptPath:     os.Getenv("LLM_SCRIPT_PATH"),
+		HTTP:           loadHTTPConfig("LLM"),
+		Prices:         parseModelPrices(os.Getenv("LLM_PRICES")),
+		Cache: types.ResponseCacheConfig{
+			Enabled: os.Getenv("LLM_CACHE_ENABLED") == "true",
+			Size:    getEnvInt("LLM_CACHE_SIZE", 256),
+			TTL:     getEnvDuration("LLM_CACHE_TTL", 24*time.Hour),
+			Dir:     os.Getenv("LLM_CACHE_DIR"),
+		},
+		Routes:   routes,
+		Backends: loadLLMBackends(routes, provider, types.LLMBackend{BaseURL: baseURL, APIKey: apiKey}),
+	}
+}
+
+func defaultLLMAPIKey(provider string) string {
+	switch provider {
+	case "gemini":
+		if apiKey := os.Getenv("GEMINI_API_KEY"); apiKey != "" {
+			return apiKey
+		}
+		return os.Getenv("GOOGLE_API_KEY")
+	case "grok":
+		return os.Getenv("XAI_API_KEY")
+	default:
+		return ""
+	}
+}
+
+// parseGeminiSafetySettings 는 "HARM_CATEGORY_DANGEROUS_CONTENT=BLOCK_ONLY_HIGH,HARM_CATEGORY_HARASSMENT=BLOCK_NONE"
+// 형식을 읽습니다. TRPG 전투 묘사처럼 기본 기준에 막히는 내레이션을 허용할 때 씁니다.
+func parseGeminiSafetySettings(raw string) []types.GeminiSafetySetting {
+	var settings []types.GeminiSafetySetting
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		category, threshold, ok := strings.Cut(entry, "=")
+		if !ok || strings.TrimSpace(category) == "" || strings.TrimSpace(threshold) == "" {
+			log.Printf("경고: GEMINI_SAFETY_SETTINGS 항목을 해석할 수 없습니다 (%s). 무시합니다.", entry)
+			continue
+		}
+		settings = append(settings, types.GeminiSafetySetting{
+			Category:  strings.TrimSpace(category),
+			Threshold: strings.TrimSpace(threshold),
+		})
+	}
+	return settings
+}
+
+// parseLLMRoutes 는 "evaluation=local:qwen2.5-7b|gemini:gemini-2.0-flash;final_answer=gemini:gemini-2.5-pro" 형식의
+// 단계별 후보 목록을 읽습니다. 앞에 적은 후보부터 시도하며, "default" 단계는 따로 지정하지 않은 단계에 쓰입니다.
+func parseLLMRoutes(raw string) map[string][]types.LLMRoute {
+	routes := map[string][]types.LLMRoute{}
+	for _, entry := range strings.Split(raw, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		stage, targets, ok := strings.Cut(entry, "=")
+		stage = strings.TrimSpace(stage)
+		if !ok || stage == "" {
+			log.Printf("경고: LLM_ROUTES 항목을 해석할 수 없습니다 (%s). 무시합니다.", entry)
+			continue
+		}
+		for _, target := range strings.Split(targets, "|") {
+			provider, model, _ := strings.Cut(strings.TrimSpace(target), ":")
+			provider = strings.TrimSpace(provider)
+			if provider == "" {
+				log.Printf("경고: LLM_ROUTES 의 %s 단계에 프로바이더가 비어 있는 후보가 있습니다. 무시합니다.", stage)
+				continue
+			}
+			routes[stage] = append(routes[stage], types.LLMRoute{Provider: provider, Model: strings.TrimSpace(model)})
+		}
+	}
+	return routes
+}
+
+// loadLLMBackends 는 라우팅에 등장하는 프로바이더마다 LLM_<PROVIDER>_BASE_URL, LLM_<PROVIDER>_API_KEY 를 읽습니다.
+// 값이 없으면 기본 프로바이더와 같은 이름일 때 LLM_BASE_URL/LLM_API_KEY 를, 그 밖에는 프로바이더별 기본 키 변수를 씁니다.
+func loadLLMBackends(routes map[string][]types.LLMRoute, primary string, primaryBackend types.LLMBackend) map[string]types.LLMBackend {
+	backends := map[string]types.LLMBackend{}
+	for _, targets := range routes {
+		for _, target := range targets {
+			if _, loaded := backends[target.Provider]; loaded {
+				continue
+			}
+
+			prefix := "LLM_" + strings.ToUpper(target.Provider)
+			backend := types.LLMBackend{
+				BaseURL: os.Getenv(prefix + "_BASE_URL"),
+				APIKey:  os.Getenv(prefix + "_API_KEY"),
+			}
+			if target.Provider == primary {
+				if backend.BaseURL == "" {
+					backend.BaseURL = primaryBackend.BaseURL
+				}
+				if backend.APIKey == "" {
+					backend.APIKey = primaryBackend.APIKey
+				}
+			}
+			if backend.APIKey == "" {
+				backend.APIKey = defaultLLMAPIKey(target.Provider)
+			}
+			backends[target.Provider] = backend
+		}
+	}
+	return backends
+}
+
+func loadEmbeddingConfig() types.EmbeddingConfig {
+	provider := os.Getenv("EMBEDDING_PROVIDER")
+	if provider == "" {
+		provider = "bge"
+	}
+
+	apiKey := os.Getenv("EMBEDDING_API_KEY")
+	if apiKey == "" && provider == "bge" {
+		apiKey = os.Getenv("HUGGING_TOKEN")
+	}
+
+	return types.EmbeddingConfig{
+		Provider:  provider,
+		Model:     os.Getenv("EMBEDDING_MODEL"),
+		BaseURL:   os.Getenv("EMBEDDING_BASE_URL"),
+		APIKey:    apiKey,
+		Dimension: getEnvInt("EMBEDDING_DIMENSION", 0),
+		CachePath: os.Getenv("EMBEDDING_CACHE_PATH"),
+		HTTP:      loadHTTPConfig("EMBEDDING"),
+	}
+}
+
+// loadHTTPConfig 는 <PREFIX>_HTTP_TIMEOUT, <PREFIX>_MAX_RETRIES 처럼 프로바이더별 접두사가 붙은
+// 환경 변수에서 재시도/속도 제한/서킷 브레이커 설정을 읽습니다.
+func loadHTTPConfig(prefix string) types.HTTPConfig {
+	return types.HTTPConfig{
+		Timeout:          getEnvDuration(prefix+"_HTTP_TIMEOUT", 0),
+		MaxRetries:       getEnvInt(prefix+"_MAX_RETRIES", 0),
+		BaseBackoff:      getEnvDuration(prefix+"_BACKOFF_BASE", 0),
+		MaxBackoff:       getEnvDuration(prefix+"_BACKOFF_MAX", 0),
+		RatePerSecond:    getEnvFloat(prefix+"_RATE_LIMIT", 0),
+		Burst:            getEnvInt(prefix+"_RATE_BURST", 1),
+		BreakerThreshold: getEnvInt(prefix+"_BREAKER_THRESHOLD", 0),
+		BreakerCooldown:  getEnvDuration(prefix+"_BREAKER_COOLDOWN", 0),
+		FixtureMode:      os.Getenv("FIXTURE_MODE"),
+		FixtureDir:       os.Getenv("FIXTURE_DIR"),
+	}
+}
+
+// parseModelPrices 는 "gemini-2.0-flash=0.10:0.40,grok-3-mini-beta=0.30:0.50" 형식의
+// 모델별 100만 토큰당 입력:출력 가격을 읽습니다.
+func parseModelPrices(raw string) map[string]types.ModelPrice {
+	prices := map[string]types.ModelPrice{}
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		model, rates, ok := strings.Cut(entry, "=")
+		inputRaw, outputRaw, hasOutput := strings.Cut(rates, ":")
+		input, inputErr := strconv.ParseFloat(strings.TrimSpace(inputRaw), 64)
+		output, outputErr := strconv.ParseFloat(strings.TrimSpace(outputRaw), 64)
+		if !ok || !hasOutput || inputErr != nil || outputErr != nil {
+			log.Printf("경고: LLM_PRICES 항목을 해석할 수 없습니다 (%s). 무시합니다.", entry)
+			continue
+		}
+		prices[strings.TrimSpace(model)] = types.ModelPrice{InputPerMillion: input, OutputPerMillion: output}
+	}
+	return prices
+}
+
+func loadConflictPolicy() types.ConflictPolicy {
+	switch policy := types.ConflictPolicy(os.Getenv("INGEST_CONFLICT_POLICY")); policy {
+	case "":
+		return types.ConflictOverwrite
+	case types.ConflictOverwrite, types.ConflictKeep:
+		return policy
+	default:
+		log.Printf("경고: INGEST_CONFLICT_POLICY 값을 해석할 수 없습니다 (%s). 기본값 %s을 사용합니다.", policy, types.ConflictOverwrite)
+		return types.ConflictOverwrite
+	}
+}
+
+func loadChunkStrategy() string {
+	raw := os.Getenv("INGEST_CHUNK_STRATEGY")
+	strategy, err := chunker.ParseStrategy(raw)
+	if err != nil {
+		log.Printf("경고: INGEST_CHUNK_STRATEGY 값을 해석할 수 없습니다 (%s). 문서 형식에 따라 고릅니다.", raw)
+		return ""
+	}
+	return string(strategy)
+}
+
+func getEnvString(key string, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func getEnvInt(key string, fallback int) int {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		log.Printf("경고: %s 값이 올바른 정수가 아닙니다 (%s). 기본값 %d을 사용합니다.", key, raw, fallback)
+		return fallback
+	}
+	return value
+}
+
+func getEnvFloat(key string, fallback float64) float64 {
+	value := getEnvFloatPtr(key)
+	if value == nil {
+		return fallback
+	}
+	return *value
+}
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+	value, err := time.ParseDuration(raw)
+	if err != nil {
+		log.Printf("경고: %s 값이 올바른 시간 형식이 아닙니다 (%s). 기본값 %v을 사용합니다.", key, raw, fallback)
+		return fallback
+	}
+	return value
+}
+
+func getEnvFloatPtr(key string) *float64 {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return nil
+	}
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		log.Printf("경고: %s 값이 올바른 실수가 아닙니다 (%s). 무시합니다.", key, raw)
+		return nil
+	}
+	return &value
+}
@@ -2,19 +2,17 @@ package db
 
 import (
 	"context"
-	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
-	"github.com/qdrant/go-client/qdrant"
+	"fmt"
 )
 
-func Cleanup(ctx context.Context, driver neo4j.DriverWithContext, qdrantCollectionsClient qdrant.CollectionsClient, collectionName string) {
-
-	session := driver.NewSession(ctx, neo4j.SessionConfig{})
-	defer session.Close(ctx)
-
-	session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
-		tx.Run(ctx, "MATCH (n) DETACH DELETE n", nil)
-		return nil, nil
-	})
-
-	qdrantCollectionsClient.Delete(ctx, &qdrant.DeleteCollection{CollectionName: collectionName})
-}
\ No newline at end of file
+// Cleanup 은 그래프 저장소가 맡은 데이터셋의 노드와 관계, 그리고 그 데이터셋의 벡터 컬렉션을 지웁니다.
+// 다른 데이터셋은 건드리지 않습니다.
+func Cleanup(ctx context.Context, graph GraphStore, vectors VectorStore, collectionName string) error {
+	if err := graph.Clear(ctx); err != nil {
+		return fmt.Errorf("그래프 데이터셋 삭제 실패: %w", err)
+	}
+	if err := vectors.DeleteCollection(ctx, collectionName); err != nil {
+		return fmt.Errorf("벡터 컬렉션 삭제 실패: %w", err)
+	}
+	return nil
+}
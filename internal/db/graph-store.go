@@ -0,0 +1,176 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"github.com/JCSong-89/trpg-rag-game/pkg/types"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// GraphNode 는 그래프에 저장할 엔티티와 그 엔티티의 벡터 포인트 id 입니다.
+type GraphNode struct {
+	Entity  types.Entity
+	PointID string
+}
+
+// GraphStore 는 지식 그래프 저장소의 공통 인터페이스입니다.
+// 조회 메서드는 모두 엔티티 이름이나 별칭(aliases)으로 시작 노드를 찾고, 관계 방향은 저장된 방향 그대로 돌려줍니다.
+// 출처를 나타내는 Document, Chunk 노드와 MENTIONED_IN 관계는 엔티티 조회(홉, 최단 경로, 중심성)에 나오지 않습니다.
+type GraphStore interface {
+	// UpsertNodes 는 entityId 가 같은 노드가 있으면 속성을 policy 에 따라 합치고, 없으면 새로 만듭니다.
+	// 별칭은 policy 와 상관없이 기존 목록에 더하고, 엔티티의 Sources 에 있는 청크마다 MENTIONED_IN 관계를 잇습니다.
+	UpsertNodes(ctx context.Context, nodes []GraphNode, policy types.ConflictPolicy) error
+	// PointIDs 는 이미 저장된 엔티티의 벡터 포인트 id 를 entityId 별로 돌려줍니다.
+	PointIDs(ctx context.Context, entityIDs []string) (map[string]string, error)
+	// UpsertRelations 는 양 끝 노드를 entityId 로 찾아 관계를 만들고, 노드가 없어 만들지 못한 관계를 돌려줍니다.
+	// 같은 두 노드 사이에 같은 타입의 관계가 이미 있으면 새로 만들지 않고 Sources 만 더합니다.
+	UpsertRelations(ctx context.Context, relations []types.Relation) ([]types.Relation, error)
+	// UpsertDocument 는 문서 노드를 만들거나 갱신하고, 그 문서의 청크 노드를 chunks 로 바꿉니다.
+	// 이전 청크에 걸려 있던 MENTIONED_IN 관계도 함께 지워집니다.
+	UpsertDocument(ctx context.Context, document types.Document, chunks []types.SourceChunk) error
+	// EntitySources 는 저장된 엔티티가 언급된 청크 id 를 entityId 별로 돌려줍니다.
+	EntitySources(ctx context.Context, entityIDs []string) (map[string][]string, error)
+	// Chunks 는 id 에 해당하는 청크를 원문과 함께 chunkIDs 순서대로 돌려줍니다. 없는 id 는 건너뜁니다.
+	Chunks(ctx context.Context, chunkIDs []string) ([]types.SourceChunk, error)
+	// MentionChunks 는 이름이 entityName 인 엔티티가 언급된 청크를 최대 limit 개 돌려줍니다.
+	MentionChunks(ctx context.Context, entityName string, limit int) ([]types.SourceChunk, error)
+	OneHop(ctx context.Context, entityName string) (*types.Subgraph, error)
+	// MultiHop 은 방향과 상관없이 maxHops 안에 닿는 노드와 그 경로의 관계를 돌려줍니다.
+	MultiHop(ctx context.Context, entityName string, maxHops int) (*types.Subgraph, error)
+	// ShortestPaths 는 두 엔티티 사이의 모든 최단 경로(방향 무시)를 하나의 서브그래프로 돌려줍니다.
+	ShortestPaths(ctx context.Context, fromName string, toName string) (*types.Subgraph, error)
+	// Centrality 는 PageRank 점수가 높은 순서로 topK 개 엔티티 이름을 돌려줍니다. topK 가 0 이하면 빈 목록입니다.
+	Centrality(ctx context.Context, topK int) ([]string, error)
+	// Clear 는 저장소가 맡은 데이터셋의 노드와 관계를 모두 지웁니다.
+	Clear(ctx context.Context) error
+	Close(ctx context.Context) error
+}
+
+func NewGraphStore(cfg types.Config) (GraphStore, error) {
+	switch cfg.Db.GraphStore {
+	case "", "neo4j":
+		return NewNeo4jGraphStore(NewNeo4jDriver(cfg), cfg.Db.Dataset), nil
+	case "memory":
+		if cfg.Db.GraphStoreDir == "" {
+			return NewMemoryGraphStore("")
+		}
+		return NewMemoryGraphStore(filepath.Join(cfg.Db.GraphStoreDir, cfg.Db.Dataset+".json"))
+	default:
+		return nil, fmt.Errorf("지원하지 않는 그래프 저장소입니다: %s", cfg.Db.GraphStore)
+	}
+}
+
+// nodeLabel 은 라벨과 관계 타입의 공백을 밑줄로 바꿉니다. Neo4j 쿼리에는 cypherName 으로 감싸 넣습니다.
+func nodeLabel(label string) string {
+	return strings.ReplaceAll(label, " ", "_")
+}
+
+// reservedNodeProperties 는 저장소가 노드 식별과 조회에 쓰는 속성입니다. 엔티티 속성에 같은 키가 있으면 버립니다.
+var reservedNodeProperties = []string{"entityId", "name", "qdrantId", "dataset", "aliases"}
+
+// nodeProperties 는 노드에 저장하는 속성 맵입니다. 예약된 키를 뺀 엔티티 속성에 entityId, name, qdrantId 를 더합니다.
+// dataset 은 저장소가, aliases 는 기존 별칭과 합쳐 따로 씁니다.
+func nodeProperties(node GraphNode) map[string]any {
+	props := make(map[string]any, len(node.Entity.Properties)+3)
+	for k, v := range node.Entity.Properties {
+		if !slices.Contains(reservedNodeProperties, k) {
+			props[k] = v
+		}
+	}
+	props["entityId"] = node.Entity.ID
+	props["name"] = node.Entity.Name
+	props["qdrantId"] = node.PointID
+	return props
+}
+
+// AppendUnique 는 list 에 없는 항목만 순서대로 덧붙입니다.
+func AppendUnique(list []string, items ...string) []string {
+	for _, item := range items {
+		if !slices.Contains(list, item) {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// stringsFromAny 는 저장소에서 읽은 문자열 목록 속성([]any 또는 []string)을 []string 으로 바꿉니다.
+func stringsFromAny(value any) []string {
+	if values, ok := value.([]string); ok {
+		return values
+	}
+	items, _ := value.([]any)
+	var values []string
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			values = append(values, s)
+		}
+	}
+	return values
+}
+
+// MergeProperties 는 기존 속성에 새 속성을 합친 새 맵을 돌려줍니다. 같은 키는 policy 에 따라 고릅니다.
+func MergeProperties(existing map[string]any, incoming map[string]any, policy types.ConflictPolicy) map[string]any {
+	merged := make(map[string]any, len(existing)+len(incoming))
+	for k, v := range existing {
+		merged[k] = v
+	}
+	for k, v := range incoming {
+		if _, exists := merged[k]; exists && policy == types.ConflictKeep {
+			continue
+		}
+		merged[k] = v
+	}
+	return merged
+}
+
+// subgraphBuilder 는 엔티티와 관계를 처음 추가된 순서대로, 중복 없이 모읍니다.
+type subgraphBuilder struct {
+	subgraph      types.Subgraph
+	seenEntities  map[string]bool
+	seenRelations map[string]bool
+}
+
+func newSubgraphBuilder() *subgraphBuilder {
+	return &subgraphBuilder{seenEntities: map[string]bool{}, seenRelations: map[string]bool{}}
+}
+
+func (b *subgraphBuilder) addEntity(entity types.Entity) {
+	if b.seenEntities[entity.ID] {
+		return
+	}
+	b.seenEntities[entity.ID] = true
+	b.subgraph.Entities = append(b.subgraph.Entities, entity)
+}
+
+// addRelation 은 같은 두 엔티티 사이의 같은 타입 관계를 하나로 봅니다. key 는 저장소가 관계를 구분하는 값입니다.
+func (b *subgraphBuilder) addRelation(key string, relation types.Relation) {
+	if b.seenRelations[key] {
+		return
+	}
+	b.seenRelations[key] = true
+	b.subgraph.Relations = append(b.subgraph.Relations, relation)
+}
+
+func (b *subgraphBuilder) build() *types.Subgraph {
+	subgraph := b.subgraph
+	return &subgraph
+}
+
+// MergeSubgraphs 는 여러 서브그래프를 엔티티 ID 와 (출발, 타입, 도착) 기준으로 중복 없이 합칩니다.
+func MergeSubgraphs(subgraphs ...*types.Subgraph) *types.Subgraph {
+	builder := newSubgraphBuilder()
+	for _, subgraph := range subgraphs {
+		if subgraph == nil {
+			continue
+		}
+		for _, entity := range subgraph.Entities {
+			builder.addEntity(entity)
+		}
+		for _, relation := range subgraph.Relations {
+			builder.addRelation(relation.Key(), relation)
+		}
+	}
+	return builder.build()
+}
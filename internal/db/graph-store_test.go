@@ -0,0 +1,36 @@
+package db
+
+import (
+	"maps"
+	"testing"
+
+	"github.com/JCSong-89/trpg-rag-game/pkg/types"
+)
+
+func TestNodePropertiesKeepReservedKeys(t *testing.T) {
+	node := GraphNode{
+		Entity: types.Entity{
+			ID:   "son_heung_min",
+			Name: "Son Heung-min",
+			Properties: map[string]any{
+				"entityId": "someone_else",
+				"name":     "Other",
+				"qdrantId": "other-point",
+				"dataset":  "other_campaign",
+				"aliases":  "Sonny",
+				"position": "Forward",
+			},
+		},
+		PointID: "point-1",
+	}
+
+	want := map[string]any{
+		"entityId": "son_heung_min",
+		"name":     "Son Heung-min",
+		"qdrantId": "point-1",
+		"position": "Forward",
+	}
+	if got := nodeProperties(node); !maps.Equal(got, want) {
+		t.Errorf("nodeProperties() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,537 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/JCSong-89/trpg-rag-game/pkg/types"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+)
+
+const (
+	pageRankDampingFactor = 0.85
+	pageRankMaxIterations = 20
+	pageRankTolerance     = 1e-7
+)
+
+type memoryNode struct {
+	id    string
+	name  string
+	label string
+	props map[string]any
+}
+
+type memoryEdge struct {
+	source  string
+	target  string
+	relType string
+}
+
+// MemoryGraphStore 는 데이터베이스 없이 프로세스 안에서 그래프를 보관하는 GraphStore 입니다.
+// Neo4j 구현과 같은 조회 의미를 갖도록 노드는 이름으로 찾고, 홉과 최단 경로는 관계 방향을 무시하며,
+// PageRank 는 GDS 기본값(감쇠 0.85, 최대 20회 반복)으로 방향 그래프에서 계산합니다.
+// 출처 문서와 청크는 엔티티 노드와 따로 보관하므로 홉, 최단 경로, 중심성 계산에 끼어들지 않습니다.
+// path 를 주면 변경할 때마다 그래프 전체를 그 JSON 파일에 저장하고, 시작할 때 다시 읽습니다.
+type MemoryGraphStore struct {
+	path string
+
+	mu          sync.RWMutex
+	nodes       map[string]*memoryNode
+	order       []string
+	edges       []memoryEdge
+	edgeSet     map[memoryEdge]bool
+	edgeSources map[memoryEdge][]string
+	incident    map[string][]int
+
+	documents      map[string]memoryDocumentSnapshot
+	documentOrder  []string
+	documentChunks map[string][]string
+	chunks         map[string]types.SourceChunk
+	mentions       map[string][]string
+}
+
+// memoryGraphSnapshot 은 파일에 저장하는 형식으로, 노드와 관계를 추가된 순서대로 담습니다.
+type memoryGraphSnapshot struct {
+	Nodes     []memoryNodeSnapshot     `json:"nodes"`
+	Edges     []memoryEdgeSnapshot     `json:"edges"`
+	Documents []memoryDocumentSnapshot `json:"documents,omitempty"`
+	Chunks    []types.SourceChunk      `json:"chunks,omitempty"`
+	Mentions  map[string][]string      `json:"mentions,omitempty"`
+}
+
+type memoryNodeSnapshot struct {
+	ID         string         `json:"id"`
+	Name       string         `json:"name"`
+	Label      string         `json:"label"`
+	Properties map[string]any `json:"properties"`
+}
+
+type memoryEdgeSnapshot struct {
+	Source  string   `json:"source"`
+	Target  string   `json:"target"`
+	Type    string   `json:"type"`
+	Sources []string `json:"sources,omitempty"`
+}
+
+type memoryDocumentSnapshot struct {
+	ID     string `json:"id"`
+	Source string `json:"source"`
+	Format string `json:"format"`
+	Title  string `json:"title"`
+	Hash   string `json:"hash"`
+}
+
+func NewMemoryGraphStore(path string) (*MemoryGraphStore, error) {
+	store := &MemoryGraphStore{path: path}
+	store.reset()
+	if path == "" {
+		return store, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("그래프 파일 읽기 실패 (%s): %w", path, err)
+	}
+	var snapshot memoryGraphSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("그래프 파일 파싱 실패 (%s): %w", path, err)
+	}
+	for _, node := range snapshot.Nodes {
+		store.order = append(store.order, node.ID)
+		store.nodes[node.ID] = &memoryNode{id: node.ID, name: node.Name, label: node.Label, props: node.Properties}
+	}
+	for _, edge := range snapshot.Edges {
+		store.addEdgeLocked(memoryEdge{source: edge.Source, target: edge.Target, relType: edge.Type}, edge.Sources)
+	}
+	for _, document := range snapshot.Documents {
+		store.documentOrder = append(store.documentOrder, document.ID)
+		store.documents[document.ID] = document
+	}
+	for _, chunk := range snapshot.Chunks {
+		store.chunks[chunk.ID] = chunk
+		store.documentChunks[chunk.DocumentID] = append(store.documentChunks[chunk.DocumentID], chunk.ID)
+	}
+	for entityID, chunkIDs := range snapshot.Mentions {
+		store.mentions[entityID] = chunkIDs
+	}
+	return store, nil
+}
+
+func (s *MemoryGraphStore) UpsertNodes(ctx context.Context, nodes []GraphNode, policy types.ConflictPolicy) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, node := range nodes {
+		id := node.Entity.ID
+		if id == "" {
+			return fmt.Errorf("entityId 가 비어 있는 노드는 저장할 수 없습니다 (%s)", node.Entity.Name)
+		}
+
+		label, props := nodeLabel(node.Entity.Label), nodeProperties(node)
+		if existing, exists := s.nodes[id]; exists {
+			props = MergeProperties(existing.props, props, policy)
+			if policy == types.ConflictKeep {
+				label = existing.label
+			}
+		} else {
+			s.order = append(s.order, id)
+		}
+		if aliases := AppendUnique(slices.Clone(stringsFromAny(props["aliases"])), node.Entity.Aliases...); len(aliases) > 0 {
+			props["aliases"] = aliases
+		}
+		name, _ := props["name"].(string)
+		s.nodes[id] = &memoryNode{id: id, name: name, label: label, props: props}
+
+		for _, chunkID := range node.Entity.Sources {
+			if _, exists := s.chunks[chunkID]; exists {
+				s.mentions[id] = AppendUnique(s.mentions[id], chunkID)
+			}
+		}
+	}
+	return s.persist()
+}
+
+func (s *MemoryGraphStore) PointIDs(ctx context.Context, entityIDs []string) (map[string]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	pointIDs := map[string]string{}
+	for _, id := range entityIDs {
+		if node, exists := s.nodes[id]; exists {
+			if pointID, _ := node.props["qdrantId"].(string); pointID != "" {
+				pointIDs[id] = pointID
+			}
+		}
+	}
+	return pointIDs, nil
+}
+
+func (s *MemoryGraphStore) UpsertRelations(ctx context.Context, relations []types.Relation) ([]types.Relation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var missing []types.Relation
+	for _, rel := range relations {
+		if s.nodes[rel.SourceName] == nil || s.nodes[rel.TargetName] == nil {
+			missing = append(missing, rel)
+			continue
+		}
+		s.addEdgeLocked(memoryEdge{source: rel.SourceName, target: rel.TargetName, relType: nodeLabel(rel.Type)}, rel.Sources)
+	}
+	return missing, s.persist()
+}
+
+// addEdgeLocked 는 같은 관계가 없을 때만 추가하고, 있으면 출처만 더합니다. 쓰기 잠금을 잡은 상태에서 호출해야 합니다.
+func (s *MemoryGraphStore) addEdgeLocked(edge memoryEdge, sources []string) {
+	if len(sources) > 0 {
+		s.edgeSources[edge] = AppendUnique(s.edgeSources[edge], sources...)
+	}
+	if s.edgeSet[edge] {
+		return
+	}
+	s.edgeSet[edge] = true
+
+	index := len(s.edges)
+	s.edges = append(s.edges, edge)
+	s.incident[edge.source] = append(s.incident[edge.source], index)
+	if edge.target != edge.source {
+		s.incident[edge.target] = append(s.incident[edge.target], index)
+	}
+}
+
+func (s *MemoryGraphStore) UpsertDocument(ctx context.Context, document types.Document, chunks []types.SourceChunk) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.documents[document.ID]; !exists {
+		s.documentOrder = append(s.documentOrder, document.ID)
+	}
+	s.documents[document.ID] = memoryDocumentSnapshot{ID: document.ID, Source: document.Source, Format: document.Format, Title: document.Title, Hash: document.Hash}
+
+	stale := map[string]bool{}
+	for _, chunkID := range s.documentChunks[document.ID] {
+		stale[chunkID] = true
+		delete(s.chunks, chunkID)
+	}
+	for entityID, chunkIDs := range s.mentions {
+		kept := chunkIDs[:0]
+		for _, chunkID := range chunkIDs {
+			if !stale[chunkID] {
+				kept = append(kept, chunkID)
+			}
+		}
+		if len(kept) == 0 {
+			delete(s.mentions, entityID)
+		} else {
+			s.mentions[entityID] = kept
+		}
+	}
+
+	s.documentChunks[document.ID] = nil
+	for _, chunk := range chunks {
+		s.chunks[chunk.ID] = chunk
+		s.documentChunks[document.ID] = append(s.documentChunks[document.ID], chunk.ID)
+	}
+	return s.persist()
+}
+
+func (s *MemoryGraphStore) EntitySources(ctx context.Context, entityIDs []string) (map[string][]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sources := map[string][]string{}
+	for _, id := range entityIDs {
+		if chunkIDs := s.mentions[id]; len(chunkIDs) > 0 {
+			sources[id] = append([]string(nil), chunkIDs...)
+		}
+	}
+	return sources, nil
+}
+
+func (s *MemoryGraphStore) Chunks(ctx context.Context, chunkIDs []string) ([]types.SourceChunk, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var chunks []types.SourceChunk
+	for _, id := range chunkIDs {
+		if chunk, exists := s.chunks[id]; exists {
+			chunks = append(chunks, chunk)
+		}
+	}
+	return chunks, nil
+}
+
+// MentionChunks 는 Neo4j 구현과 같이 문서 id, 청크 순번 순서로 돌려줍니다.
+func (s *MemoryGraphStore) MentionChunks(ctx context.Context, entityName string, limit int) ([]types.SourceChunk, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var chunkIDs []string
+	for _, id := range s.idsByName(entityName) {
+		chunkIDs = AppendUnique(chunkIDs, s.mentions[id]...)
+	}
+	chunks := make([]types.SourceChunk, 0, len(chunkIDs))
+	for _, id := range chunkIDs {
+		chunks = append(chunks, s.chunks[id])
+	}
+	sort.Slice(chunks, func(i, j int) bool {
+		if chunks[i].DocumentID != chunks[j].DocumentID {
+			return chunks[i].DocumentID < chunks[j].DocumentID
+		}
+		return chunks[i].Index < chunks[j].Index
+	})
+	return chunks[:min(max(limit, 0), len(chunks))], nil
+}
+
+func (s *MemoryGraphStore) OneHop(ctx context.Context, entityName string) (*types.Subgraph, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	builder := newSubgraphBuilder()
+	for _, id := range s.idsByName(entityName) {
+		for _, index := range s.incident[id] {
+			s.addEdge(builder, index)
+		}
+	}
+	return builder.build(), nil
+}
+
+// MultiHop 은 시작 노드에서 너비 우선으로 maxHops 단계까지 나아가며, 지나간 관계를 모두 담습니다.
+func (s *MemoryGraphStore) MultiHop(ctx context.Context, entityName string, maxHops int) (*types.Subgraph, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	maxHops = max(maxHops, 1)
+	depth := map[string]int{}
+	var queue []string
+	for _, id := range s.idsByName(entityName) {
+		depth[id] = 0
+		queue = append(queue, id)
+	}
+
+	builder := newSubgraphBuilder()
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if depth[current] >= maxHops {
+			continue
+		}
+		for _, index := range s.incident[current] {
+			s.addEdge(builder, index)
+			neighbor := s.edges[index].other(current)
+			if _, visited := depth[neighbor]; !visited {
+				depth[neighbor] = depth[current] + 1
+				queue = append(queue, neighbor)
+			}
+		}
+	}
+	return builder.build(), nil
+}
+
+// ShortestPaths 는 출발 노드들에서 너비 우선 탐색으로 거리를 구한 뒤,
+// 가장 가까운 도착 노드에서 거리가 1씩 줄어드는 관계만 따라 되돌아가며 모든 최단 경로를 모읍니다.
+func (s *MemoryGraphStore) ShortestPaths(ctx context.Context, fromName string, toName string) (*types.Subgraph, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	builder := newSubgraphBuilder()
+	targets := map[string]bool{}
+	for _, id := range s.idsByName(toName) {
+		targets[id] = true
+	}
+
+	for _, source := range s.idsByName(fromName) {
+		distance := map[string]int{source: 0}
+		queue := []string{source}
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+			for _, index := range s.incident[current] {
+				neighbor := s.edges[index].other(current)
+				if _, visited := distance[neighbor]; !visited {
+					distance[neighbor] = distance[current] + 1
+					queue = append(queue, neighbor)
+				}
+			}
+		}
+
+		for target := range targets {
+			if target == source {
+				continue
+			}
+			if _, reachable := distance[target]; reachable {
+				s.collectShortestPathEdges(builder, distance, target)
+			}
+		}
+	}
+	return builder.build(), nil
+}
+
+func (s *MemoryGraphStore) collectShortestPathEdges(builder *subgraphBuilder, distance map[string]int, target string) {
+	frontier := map[string]bool{target: true}
+	for d := distance[target]; d > 0; d-- {
+		next := map[string]bool{}
+		for current := range frontier {
+			for _, index := range s.incident[current] {
+				previous := s.edges[index].other(current)
+				if dist, ok := distance[previous]; ok && dist == d-1 {
+					s.addEdge(builder, index)
+					next[previous] = true
+				}
+			}
+		}
+		frontier = next
+	}
+}
+
+func (s *MemoryGraphStore) Centrality(ctx context.Context, topK int) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	outDegree := map[string]int{}
+	for _, edge := range s.edges {
+		outDegree[edge.source]++
+	}
+
+	scores := map[string]float64{}
+	for _, id := range s.order {
+		scores[id] = 1 - pageRankDampingFactor
+	}
+	for iteration := 0; iteration < pageRankMaxIterations; iteration++ {
+		next := make(map[string]float64, len(scores))
+		for _, id := range s.order {
+			next[id] = 1 - pageRankDampingFactor
+		}
+		for _, edge := range s.edges {
+			next[edge.target] += pageRankDampingFactor * scores[edge.source] / float64(outDegree[edge.source])
+		}
+
+		delta := 0.0
+		for id, score := range next {
+			delta = math.Max(delta, math.Abs(score-scores[id]))
+		}
+		scores = next
+		if delta < pageRankTolerance {
+			break
+		}
+	}
+
+	ids := append([]string(nil), s.order...)
+	sort.SliceStable(ids, func(i, j int) bool { return scores[ids[i]] > scores[ids[j]] })
+
+	var names []string
+	for _, id := range ids[:min(max(topK, 0), len(ids))] {
+		names = append(names, s.nodes[id].name)
+	}
+	return names, nil
+}
+
+func (s *MemoryGraphStore) Clear(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.reset()
+	if s.path == "" {
+		return nil
+	}
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("그래프 파일 삭제 실패 (%s): %w", s.path, err)
+	}
+	return nil
+}
+
+func (s *MemoryGraphStore) reset() {
+	s.nodes = map[string]*memoryNode{}
+	s.order = nil
+	s.edges = nil
+	s.edgeSet = map[memoryEdge]bool{}
+	s.edgeSources = map[memoryEdge][]string{}
+	s.incident = map[string][]int{}
+	s.documents = map[string]memoryDocumentSnapshot{}
+	s.documentOrder = nil
+	s.documentChunks = map[string][]string{}
+	s.chunks = map[string]types.SourceChunk{}
+	s.mentions = map[string][]string{}
+}
+
+// persist 는 쓰기 잠금을 잡은 상태에서 호출해야 합니다. 임시 파일에 쓴 뒤 이름을 바꿔 통째로 교체합니다.
+func (s *MemoryGraphStore) persist() error {
+	if s.path == "" {
+		return nil
+	}
+
+	snapshot := memoryGraphSnapshot{Nodes: []memoryNodeSnapshot{}, Edges: []memoryEdgeSnapshot{}, Mentions: s.mentions}
+	for _, id := range s.order {
+		node := s.nodes[id]
+		snapshot.Nodes = append(snapshot.Nodes, memoryNodeSnapshot{ID: node.id, Name: node.name, Label: node.label, Properties: node.props})
+	}
+	for _, edge := range s.edges {
+		snapshot.Edges = append(snapshot.Edges, memoryEdgeSnapshot{Source: edge.source, Target: edge.target, Type: edge.relType, Sources: s.edgeSources[edge]})
+	}
+	for _, id := range s.documentOrder {
+		snapshot.Documents = append(snapshot.Documents, s.documents[id])
+		for _, chunkID := range s.documentChunks[id] {
+			snapshot.Chunks = append(snapshot.Chunks, s.chunks[chunkID])
+		}
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("그래프 직렬화 실패: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("그래프 디렉터리 생성 실패 (%s): %w", s.path, err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("그래프 파일 쓰기 실패 (%s): %w", s.path, err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("그래프 파일 교체 실패 (%s): %w", s.path, err)
+	}
+	return nil
+}
+
+func (s *MemoryGraphStore) Close(ctx context.Context) error {
+	return nil
+}
+
+// idsByName 은 이름이나 별칭이 name 인 노드를 추가된 순서대로 찾습니다.
+func (s *MemoryGraphStore) idsByName(name string) []string {
+	var ids []string
+	for _, id := range s.order {
+		node := s.nodes[id]
+		if node.name == name || slices.Contains(stringsFromAny(node.props["aliases"]), name) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func (s *MemoryGraphStore) addEdge(builder *subgraphBuilder, index int) {
+	edge := s.edges[index]
+	source, target := s.nodes[edge.source], s.nodes[edge.target]
+	builder.addEntity(source.entity())
+	builder.addEntity(target.entity())
+	builder.addRelation(fmt.Sprint(index), types.Relation{SourceName: source.name, TargetName: target.name, Type: edge.relType, Sources: s.edgeSources[edge]})
+}
+
+func (n *memoryNode) entity() types.Entity {
+	return types.Entity{ID: n.id, Name: n.name, Label: n.label, Properties: n.props, Aliases: stringsFromAny(n.props["aliases"])}
+}
+
+func (e memoryEdge) other(id string) string {
+	if e.source == id {
+		return e.target
+	}
+	return e.source
+}
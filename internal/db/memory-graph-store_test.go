@@ -0,0 +1,33 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/JCSong-89/trpg-rag-game/pkg/types"
+)
+
+func TestMemoryCentralityTopK(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewMemoryGraphStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes := []GraphNode{
+		{Entity: types.Entity{ID: "a", Name: "A", Label: "Person"}},
+		{Entity: types.Entity{ID: "b", Name: "B", Label: "Person"}},
+	}
+	if err := store.UpsertNodes(ctx, nodes, types.ConflictOverwrite); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct{ topK, want int }{{-1, 0}, {0, 0}, {1, 1}, {10, 2}} {
+		names, err := store.Centrality(ctx, tt.topK)
+		if err != nil {
+			t.Fatalf("Centrality(%d) error = %v", tt.topK, err)
+		}
+		if len(names) != tt.want {
+			t.Errorf("Centrality(%d) = %v, want %d names", tt.topK, names, tt.want)
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type memoryCollection struct {
+	Dimension int                     `json:"dimension"`
+	Points    map[string]memoryVector `json:"points"`
+}
+
+// memoryVector 의 Vector 는 길이 1로 정규화해 저장하므로 검색은 내적만 계산합니다.
+type memoryVector struct {
+	Vector  []float32      `json:"vector"`
+	Payload map[string]any `json:"payload,omitempty"`
+}
+
+// MemoryVectorStore 는 Qdrant 없이 프로세스 안에서 모든 벡터와 비교하는(brute-force) VectorStore 입니다.
+// 작은 캠페인과 로컬 실행을 위한 것으로, dir 을 주면 컬렉션마다 <dir>/<컬렉션>.json 파일에 저장하고
+// 시작할 때 다시 읽습니다. 파일은 변경이 있을 때마다 임시 파일에 쓴 뒤 이름을 바꿔 통째로 교체합니다.
+type MemoryVectorStore struct {
+	dir string
+
+	mu          sync.RWMutex
+	collections map[string]*memoryCollection
+}
+
+func NewMemoryVectorStore(dir string) (*MemoryVectorStore, error) {
+	store := &MemoryVectorStore{dir: dir, collections: map[string]*memoryCollection{}}
+	if dir == "" {
+		return store, nil
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("벡터 저장소 디렉터리 생성 실패 (%s): %w", dir, err)
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range files {
+		name, err := url.PathUnescape(strings.TrimSuffix(filepath.Base(path), ".json"))
+		if err != nil {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("벡터 저장소 파일 읽기 실패 (%s): %w", path, err)
+		}
+		var collection memoryCollection
+		if err := json.Unmarshal(data, &collection); err != nil {
+			return nil, fmt.Errorf("벡터 저장소 파일 파싱 실패 (%s): %w", path, err)
+		}
+		if collection.Points == nil {
+			collection.Points = map[string]memoryVector{}
+		}
+		store.collections[name] = &collection
+	}
+	return store, nil
+}
+
+func (s *MemoryVectorStore) CreateCollection(ctx context.Context, collection string, dimension int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.collections[collection]; exists {
+		return nil
+	}
+	if dimension <= 0 {
+		return fmt.Errorf("벡터 차원은 1 이상이어야 합니다 (%s: %d)", collection, dimension)
+	}
+	s.collections[collection] = &memoryCollection{Dimension: dimension, Points: map[string]memoryVector{}}
+	return s.persist(collection)
+}
+
+func (s *MemoryVectorStore) DeleteCollection(ctx context.Context, collection string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.collections, collection)
+	if s.dir == "" {
+		return nil
+	}
+	if err := os.Remove(s.collectionPath(collection)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("벡터 저장소 파일 삭제 실패 (%s): %w", collection, err)
+	}
+	return nil
+}
+
+func (s *MemoryVectorStore) Upsert(ctx context.Context, collection string, points []VectorPoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, err := s.collection(collection)
+	if err != nil {
+		return err
+	}
+	for _, point := range points {
+		if len(point.Vector) != c.Dimension {
+			return fmt.Errorf("벡터 차원이 컬렉션과 다릅니다 (%s: 컬렉션 %d, 입력 %d)", point.ID, c.Dimension, len(point.Vector))
+		}
+	}
+	for _, point := range points {
+		c.Points[point.ID] = memoryVector{Vector: normalizeVector(point.Vector), Payload: point.Payload}
+	}
+	return s.persist(collection)
+}
+
+func (s *MemoryVectorStore) Delete(ctx context.Context, collection string, ids []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, err := s.collection(collection)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		delete(c.Points, id)
+	}
+	return s.persist(collection)
+}
+
+func (s *MemoryVectorStore) Search(ctx context.Context, collection string, vector []float32, topK int, filter VectorFilter) ([]VectorMatch, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	c, err := s.collection(collection)
+	if err != nil {
+		return nil, err
+	}
+	if len(vector) != c.Dimension {
+		return nil, fmt.Errorf("검색 벡터 차원이 컬렉션과 다릅니다 (컬렉션 %d, 입력 %d)", c.Dimension, len(vector))
+	}
+
+	query := normalizeVector(vector)
+	var matches []VectorMatch
+	for id, point := range c.Points {
+		if !payloadMatches(point.Payload, filter) {
+			continue
+		}
+		var score float32
+		for i, v := range point.Vector {
+			score += v * query[i]
+		}
+		matches = append(matches, VectorMatch{ID: id, Score: score, Payload: point.Payload})
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].ID < matches[j].ID
+	})
+	return matches[:min(max(topK, 0), len(matches))], nil
+}
+
+func (s *MemoryVectorStore) Count(ctx context.Context, collection string, filter VectorFilter) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	c, err := s.collection(collection)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, point := range c.Points {
+		if payloadMatches(point.Payload, filter) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (s *MemoryVectorStore) Close() error {
+	return nil
+}
+
+func (s *MemoryVectorStore) collection(name string) (*memoryCollection, error) {
+	c, ok := s.collections[name]
+	if !ok {
+		return nil, fmt.Errorf("벡터 컬렉션을 찾을 수 없습니다: %s", name)
+	}
+	return c, nil
+}
+
+func (s *MemoryVectorStore) collectionPath(name string) string {
+	return filepath.Join(s.dir, url.PathEscape(name)+".json")
+}
+
+// persist 는 잠금을 잡은 상태에서 호출해야 합니다.
+func (s *MemoryVectorStore) persist(name string) error {
+	if s.dir == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(s.collections[name])
+	if err != nil {
+		return fmt.Errorf("벡터 컬렉션 직렬화 실패 (%s): %w", name, err)
+	}
+	path := s.collectionPath(name)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("벡터 저장소 파일 쓰기 실패 (%s): %w", name, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("벡터 저장소 파일 교체 실패 (%s): %w", name, err)
+	}
+	return nil
+}
+
+func normalizeVector(vector []float32) []float32 {
+	var norm float64
+	for _, v := range vector {
+		norm += float64(v) * float64(v)
+	}
+	normalized := make([]float32, len(vector))
+	if norm == 0 {
+		return normalized
+	}
+	scale := float32(1 / math.Sqrt(norm))
+	for i, v := range vector {
+		normalized[i] = v * scale
+	}
+	return normalized
+}
+
+func payloadMatches(payload map[string]any, filter VectorFilter) bool {
+	for field, want := range filter {
+		if !payloadValueMatches(payload[field], want) {
+			return false
+		}
+	}
+	return true
+}
+
+func payloadValueMatches(value any, want string) bool {
+	switch v := value.(type) {
+	case string:
+		return v == want
+	case []string:
+		for _, item := range v {
+			if item == want {
+				return true
+			}
+		}
+	case []any:
+		for _, item := range v {
+			if s, ok := item.(string); ok && s == want {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,567 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"github.com/JCSong-89/trpg-rag-game/pkg/types"
+	"github.com/google/uuid"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"log"
+	"strings"
+	"sync"
+)
+
+// Neo4jGraphStore 는 Cypher 로 Neo4j 에 그래프를 저장하고 조회합니다. Centrality 는 GDS 플러그인이 필요합니다.
+// 모든 노드에 dataset 속성을 붙이고 모든 쿼리를 그 데이터셋으로 한정하므로, 여러 캠페인이 한 데이터베이스를 나눠 쓸 수 있습니다.
+type Neo4jGraphStore struct {
+	driver  neo4j.DriverWithContext
+	dataset string
+
+	schemaMu    sync.Mutex
+	schemaReady bool
+}
+
+// entityLabel 은 모든 엔티티 노드에 붙이는 공통 라벨입니다. (entityId, dataset) 유일성 제약이 이 라벨에 걸립니다.
+const entityLabel = "Entity"
+
+func NewNeo4jGraphStore(driver neo4j.DriverWithContext, dataset string) *Neo4jGraphStore {
+	return &Neo4jGraphStore{driver: driver, dataset: dataset}
+}
+
+// UpsertNodes 는 Entity 라벨과 entityId 로 MERGE 합니다. ConflictKeep 이면 새 속성을 덮어쓴 뒤 기존 속성을 다시 덮어써 기존 값을 살립니다.
+// 라벨은 MemoryGraphStore 와 같이 새 노드거나 ConflictOverwrite 일 때만 바꾸며, 이때 이전 라벨은 지웁니다.
+func (s *Neo4jGraphStore) UpsertNodes(ctx context.Context, nodes []GraphNode, policy types.ConflictPolicy) error {
+	if err := s.ensureSchema(ctx); err != nil {
+		return err
+	}
+
+	session := s.driver.NewSession(ctx, neo4j.SessionConfig{})
+	defer session.Close(ctx)
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		for _, node := range nodes {
+			result, err := tx.Run(ctx, `
+                MATCH (e:Entity {entityId: $entityId, dataset: $dataset})
+                RETURN labels(e) AS labels
+            `, map[string]any{"entityId": node.Entity.ID, "dataset": s.dataset})
+			if err != nil {
+				return nil, fmt.Errorf("Neo4j 노드 라벨 조회 실패 (%s): %w", node.Entity.Name, err)
+			}
+			records, err := result.Collect(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("Neo4j 노드 라벨 조회 실패 (%s): %w", node.Entity.Name, err)
+			}
+
+			query := `
+                MERGE (e:Entity {entityId: $entityId, dataset: $dataset})
+                WITH e, properties(e) AS existing
+            `
+			if len(records) == 0 || policy != types.ConflictKeep {
+				var existingLabels []string
+				if len(records) > 0 {
+					existingLabels = stringsFromAny(records[0].Values[0])
+				}
+				query += relabelClause(existingLabels, node.Entity.Label)
+			}
+			query += "SET e += $props\n"
+			if policy == types.ConflictKeep {
+				query += "SET e += existing\n"
+			}
+			query += `
+                SET e.aliases = coalesce(existing.aliases, []) + [alias IN $aliases WHERE NOT alias IN coalesce(existing.aliases, [])]
+                WITH e
+                UNWIND $sources AS chunkId
+                MATCH (c:Chunk {chunkId: chunkId, dataset: $dataset})
+                MERGE (e)-[:MENTIONED_IN]->(c)
+            `
+
+			params := map[string]any{
+				"entityId": node.Entity.ID,
+				"dataset":  s.dataset,
+				"props":    nodeProperties(node),
+				"aliases":  anyList(node.Entity.Aliases),
+				"sources":  anyList(node.Entity.Sources),
+			}
+			if _, err := tx.Run(ctx, query, params); err != nil {
+				return nil, fmt.Errorf("Neo4j 노드 병합 실패 (%s): %w", node.Entity.Name, err)
+			}
+		}
+		return nil, nil
+	})
+	return err
+}
+
+// relabelClause 는 Entity 밖의 기존 라벨을 지우고 label 을 붙이는 Cypher 절입니다.
+func relabelClause(existingLabels []string, label string) string {
+	label = nodeLabel(label)
+	var clause strings.Builder
+	for _, existing := range existingLabels {
+		if existing != entityLabel && existing != label {
+			clause.WriteString("REMOVE e:" + cypherName(existing) + "\n")
+		}
+	}
+	if label != "" && label != entityLabel {
+		clause.WriteString("SET e:" + cypherName(label) + "\n")
+	}
+	return clause.String()
+}
+
+// ensureSchema 는 엔티티 노드의 (entityId, dataset) 유일성 제약을 만듭니다. 제약이 생기기 전에 저장된 엔티티 노드에는
+// 먼저 Entity 라벨을 붙입니다. 스키마 변경은 데이터 변경과 한 트랜잭션에 둘 수 없어 각각 따로 실행하며, 성공하면 다시 하지 않습니다.
+func (s *Neo4jGraphStore) ensureSchema(ctx context.Context) error {
+	s.schemaMu.Lock()
+	defer s.schemaMu.Unlock()
+	if s.schemaReady {
+		return nil
+	}
+
+	session := s.driver.NewSession(ctx, neo4j.SessionConfig{})
+	defer session.Close(ctx)
+
+	for _, query := range []string{
+		"MATCH (e) WHERE e.entityId IS NOT NULL AND e.dataset IS NOT NULL AND NOT e:Entity SET e:Entity",
+		"CREATE CONSTRAINT entity_key IF NOT EXISTS FOR (e:Entity) REQUIRE (e.entityId, e.dataset) IS UNIQUE",
+	} {
+		result, err := session.Run(ctx, query, nil)
+		if err == nil {
+			_, err = result.Consume(ctx)
+		}
+		if err != nil {
+			return fmt.Errorf("Neo4j 엔티티 제약 생성 실패: %w", err)
+		}
+	}
+	s.schemaReady = true
+	return nil
+}
+
+func (s *Neo4jGraphStore) PointIDs(ctx context.Context, entityIDs []string) (map[string]string, error) {
+	records, err := s.read(ctx, `
+        MATCH (e:Entity {dataset: $dataset})
+        WHERE e.entityId IN $entityIds AND e.qdrantId IS NOT NULL AND e.qdrantId <> ''
+        RETURN e.entityId AS entityId, e.qdrantId AS pointId
+    `, map[string]any{"entityIds": entityIDs, "dataset": s.dataset})
+	if err != nil {
+		return nil, fmt.Errorf("기존 포인트 id 조회 실패: %w", err)
+	}
+
+	pointIDs := make(map[string]string, len(records))
+	for _, record := range records {
+		entityID, _ := record.Values[0].(string)
+		pointID, _ := record.Values[1].(string)
+		pointIDs[entityID] = pointID
+	}
+	return pointIDs, nil
+}
+
+func (s *Neo4jGraphStore) UpsertRelations(ctx context.Context, relations []types.Relation) ([]types.Relation, error) {
+	session := s.driver.NewSession(ctx, neo4j.SessionConfig{})
+	defer session.Close(ctx)
+
+	result, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		var missing []types.Relation
+		for _, rel := range relations {
+			query := fmt.Sprintf(`
+                MATCH (a:Entity {entityId: $sourceId, dataset: $dataset})
+                MATCH (b:Entity {entityId: $targetId, dataset: $dataset})
+                MERGE (a)-[r:%s]->(b)
+                SET r.sources = coalesce(r.sources, []) + [id IN $sources WHERE NOT id IN coalesce(r.sources, [])]
+                RETURN count(r) AS merged
+            `, cypherName(rel.Type))
+
+			result, err := tx.Run(ctx, query, map[string]any{
+				"sourceId": rel.SourceName,
+				"targetId": rel.TargetName,
+				"dataset":  s.dataset,
+				"sources":  anyList(rel.Sources),
+			})
+			if err != nil {
+				// 트랜잭션 내에서 에러가 발생하면 전체가 롤백됩니다.
+				return nil, fmt.Errorf("관계 병합 쿼리 실행 실패 (%s->%s): %w", rel.SourceName, rel.TargetName, err)
+			}
+
+			record, err := result.Single(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("결과 읽기 실패 (%s->%s): %w", rel.SourceName, rel.TargetName, err)
+			}
+			if merged, _ := record.Values[0].(int64); merged == 0 {
+				missing = append(missing, rel)
+			}
+		}
+		return missing, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]types.Relation), nil
+}
+
+func (s *Neo4jGraphStore) UpsertDocument(ctx context.Context, document types.Document, chunks []types.SourceChunk) error {
+	session := s.driver.NewSession(ctx, neo4j.SessionConfig{})
+	defer session.Close(ctx)
+
+	chunkProps := make([]any, len(chunks))
+	for i, chunk := range chunks {
+		chunkProps[i] = chunkProperties(chunk)
+	}
+	params := map[string]any{
+		"documentId": document.ID,
+		"dataset":    s.dataset,
+		"props": map[string]any{
+			"source": document.Source,
+			"format": document.Format,
+			"title":  document.Title,
+			"hash":   document.Hash,
+		},
+		"chunks": chunkProps,
+	}
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		if _, err := tx.Run(ctx, `
+            MERGE (d:Document {documentId: $documentId, dataset: $dataset})
+            SET d += $props
+            WITH d
+            OPTIONAL MATCH (old:Chunk)-[:IN_DOCUMENT]->(d)
+            DETACH DELETE old
+        `, params); err != nil {
+			return nil, fmt.Errorf("문서 노드 병합 실패 (%s): %w", document.ID, err)
+		}
+		if _, err := tx.Run(ctx, `
+            MATCH (d:Document {documentId: $documentId, dataset: $dataset})
+            UNWIND $chunks AS chunk
+            CREATE (c:Chunk {dataset: $dataset})
+            SET c += chunk
+            CREATE (c)-[:IN_DOCUMENT]->(d)
+        `, params); err != nil {
+			return nil, fmt.Errorf("청크 노드 생성 실패 (%s): %w", document.ID, err)
+		}
+		return nil, nil
+	})
+	return err
+}
+
+func (s *Neo4jGraphStore) EntitySources(ctx context.Context, entityIDs []string) (map[string][]string, error) {
+	records, err := s.read(ctx, `
+        MATCH (e:Entity {dataset: $dataset})-[:MENTIONED_IN]->(c:Chunk)
+        WHERE e.entityId IN $entityIds
+        RETURN e.entityId AS entityId, collect(c.chunkId) AS chunkIds
+    `, map[string]any{"entityIds": entityIDs, "dataset": s.dataset})
+	if err != nil {
+		return nil, fmt.Errorf("엔티티 출처 조회 실패: %w", err)
+	}
+
+	sources := make(map[string][]string, len(records))
+	for _, record := range records {
+		entityID, _ := record.Values[0].(string)
+		sources[entityID] = stringsFromAny(record.Values[1])
+	}
+	return sources, nil
+}
+
+func (s *Neo4jGraphStore) Chunks(ctx context.Context, chunkIDs []string) ([]types.SourceChunk, error) {
+	records, err := s.read(ctx, `
+        MATCH (c:Chunk {dataset: $dataset})
+        WHERE c.chunkId IN $chunkIds
+        RETURN c
+    `, map[string]any{"chunkIds": chunkIDs, "dataset": s.dataset})
+	if err != nil {
+		return nil, fmt.Errorf("청크 조회 실패: %w", err)
+	}
+
+	byID := make(map[string]types.SourceChunk, len(records))
+	for _, record := range records {
+		chunk := chunkFromNode(record.Values[0].(neo4j.Node))
+		byID[chunk.ID] = chunk
+	}
+	var chunks []types.SourceChunk
+	for _, id := range chunkIDs {
+		if chunk, ok := byID[id]; ok {
+			chunks = append(chunks, chunk)
+		}
+	}
+	return chunks, nil
+}
+
+func (s *Neo4jGraphStore) MentionChunks(ctx context.Context, entityName string, limit int) ([]types.SourceChunk, error) {
+	records, err := s.read(ctx, `
+        MATCH (e {dataset: $dataset})-[:MENTIONED_IN]->(c:Chunk)
+        WHERE e.entityId IS NOT NULL AND (e.name = $entityName OR $entityName IN coalesce(e.aliases, []))
+        RETURN DISTINCT c
+        ORDER BY c.documentId, c.index
+        LIMIT $limit
+    `, map[string]any{"entityName": entityName, "dataset": s.dataset, "limit": int64(max(limit, 0))})
+	if err != nil {
+		return nil, fmt.Errorf("'%s' 언급 청크 조회 실패: %w", entityName, err)
+	}
+
+	chunks := make([]types.SourceChunk, 0, len(records))
+	for _, record := range records {
+		chunks = append(chunks, chunkFromNode(record.Values[0].(neo4j.Node)))
+	}
+	return chunks, nil
+}
+
+func (s *Neo4jGraphStore) OneHop(ctx context.Context, entityName string) (*types.Subgraph, error) {
+	records, err := s.read(ctx, `
+        MATCH (e {dataset: $dataset})-[r]-(neighbor)
+        WHERE e.entityId IS NOT NULL AND neighbor.entityId IS NOT NULL
+          AND (e.name = $entityName OR $entityName IN coalesce(e.aliases, []))
+        RETURN e, r, neighbor
+    `, map[string]any{"entityName": entityName, "dataset": s.dataset})
+	if err != nil {
+		return nil, fmt.Errorf("one-hop 서브그래프 생성 실패: %w", err)
+	}
+
+	builder := newSubgraphBuilder()
+	for _, record := range records {
+		startNodeRecord, _ := record.Get("e")
+		relationshipRecord, _ := record.Get("r")
+		endNodeRecord, _ := record.Get("neighbor")
+
+		startNode := startNodeRecord.(neo4j.Node)
+		relationship := relationshipRecord.(neo4j.Relationship)
+		endNode := endNodeRecord.(neo4j.Node)
+
+		source, target := startNode, endNode
+		if relationship.StartElementId != startNode.ElementId {
+			source, target = endNode, startNode
+		}
+		builder.addEntity(entityFromNode(startNode))
+		builder.addEntity(entityFromNode(endNode))
+		builder.addRelation(relationship.ElementId, types.Relation{
+			SourceName: entityFromNode(source).Name,
+			TargetName: entityFromNode(target).Name,
+			Type:       relationship.Type,
+			Sources:    stringsFromAny(relationship.Props["sources"]),
+		})
+	}
+	return builder.build(), nil
+}
+
+func (s *Neo4jGraphStore) MultiHop(ctx context.Context, entityName string, maxHops int) (*types.Subgraph, error) {
+	records, err := s.read(ctx, fmt.Sprintf(`
+        MATCH (e {dataset: $dataset})
+        WHERE e.entityId IS NOT NULL AND (e.name = $entityName OR $entityName IN coalesce(e.aliases, []))
+        MATCH p=(e)-[*1..%d]-(neighbor)
+        WHERE e <> neighbor AND all(n IN nodes(p) WHERE n.entityId IS NOT NULL)
+        WITH collect(p) AS paths
+        RETURN reduce(ns = [], p IN paths | ns + nodes(p)) AS nodes,
+               reduce(rs = [], p IN paths | rs + relationships(p)) AS rels
+    `, max(maxHops, 1)), map[string]any{"entityName": entityName, "dataset": s.dataset})
+	if err != nil {
+		return nil, fmt.Errorf("Multi-hop 서브그래프 생성 실패: %w", err)
+	}
+	return subgraphFromPathRecords(records), nil
+}
+
+func (s *Neo4jGraphStore) ShortestPaths(ctx context.Context, fromName string, toName string) (*types.Subgraph, error) {
+	records, err := s.read(ctx, `
+        MATCH (a {dataset: $dataset}), (b {dataset: $dataset})
+        WHERE a <> b AND a.entityId IS NOT NULL AND b.entityId IS NOT NULL
+          AND (a.name = $fromName OR $fromName IN coalesce(a.aliases, []))
+          AND (b.name = $toName OR $toName IN coalesce(b.aliases, []))
+        MATCH p = allShortestPaths((a)-[*]-(b))
+        WHERE all(n IN nodes(p) WHERE n.entityId IS NOT NULL)
+        WITH collect(p) AS paths
+        RETURN reduce(ns = [], p IN paths | ns + nodes(p)) AS nodes,
+               reduce(rs = [], p IN paths | rs + relationships(p)) AS rels
+    `, map[string]any{"fromName": fromName, "toName": toName, "dataset": s.dataset})
+	if err != nil {
+		return nil, fmt.Errorf("최단 경로 조회 실패 (%s -> %s): %w", fromName, toName, err)
+	}
+	return subgraphFromPathRecords(records), nil
+}
+
+// Centrality 는 요청마다 데이터셋의 노드와 관계만 GDS 임시 그래프로 프로젝션해 PageRank 를 계산하고, 끝나면 지웁니다.
+func (s *Neo4jGraphStore) Centrality(ctx context.Context, topK int) ([]string, error) {
+	session := s.driver.NewSession(ctx, neo4j.SessionConfig{})
+	defer session.Close(ctx)
+
+	graphName := "gds-temp-graph-" + uuid.New().String()
+
+	defer func() {
+		_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+			_, err := tx.Run(ctx, `
+                CALL gds.graph.exists($graphName) YIELD exists
+                WHERE exists
+                CALL gds.graph.drop($graphName, false) YIELD graphName
+                RETURN graphName
+            `, map[string]any{"graphName": graphName})
+			return nil, err
+		})
+		if err != nil {
+			log.Printf("경고: GDS 임시 그래프 '%s' 정리 실패: %v", graphName, err)
+		}
+	}()
+
+	result, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		_, err := tx.Run(ctx, `
+            MATCH (source {dataset: $dataset})
+            WHERE source.entityId IS NOT NULL
+            OPTIONAL MATCH (source)-[r]->(target {dataset: $dataset})
+            WHERE target.entityId IS NOT NULL
+            WITH gds.graph.project($graphName, source, target) AS g
+            RETURN g.graphName
+        `, map[string]any{"graphName": graphName, "dataset": s.dataset})
+		if err != nil {
+			return nil, fmt.Errorf("GDS 그래프 프로젝션 실패: %w", err)
+		}
+
+		records, err := tx.Run(ctx, `
+            CALL gds.pageRank.stream($graphName)
+            YIELD nodeId, score
+            WITH gds.util.asNode(nodeId) AS topNode, score
+            ORDER BY score DESC
+            LIMIT $topK
+            RETURN topNode.name AS name
+        `, map[string]any{"graphName": graphName, "topK": int64(max(topK, 0))})
+		if err != nil {
+			return nil, err
+		}
+		return records.Collect(ctx)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("PageRank 계산 실패: %w", err)
+	}
+
+	var names []string
+	for _, record := range result.([]*neo4j.Record) {
+		if name, ok := record.Values[0].(string); ok {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// Clear 는 이 저장소의 데이터셋에 속한 노드와 관계만 지웁니다.
+func (s *Neo4jGraphStore) Clear(ctx context.Context) error {
+	session := s.driver.NewSession(ctx, neo4j.SessionConfig{})
+	defer session.Close(ctx)
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		_, err := tx.Run(ctx, "MATCH (n {dataset: $dataset}) DETACH DELETE n", map[string]any{"dataset": s.dataset})
+		return nil, err
+	})
+	return err
+}
+
+func (s *Neo4jGraphStore) Close(ctx context.Context) error {
+	return s.driver.Close(ctx)
+}
+
+func (s *Neo4jGraphStore) read(ctx context.Context, query string, params map[string]any) ([]*neo4j.Record, error) {
+	session := s.driver.NewSession(ctx, neo4j.SessionConfig{})
+	defer session.Close(ctx)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		records, err := tx.Run(ctx, query, params)
+		if err != nil {
+			return nil, err
+		}
+		return records.Collect(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]*neo4j.Record), nil
+}
+
+// entityFromNode 는 ID 로 저장된 entityId 속성을 쓰고, 없으면 Neo4j 내부 id 를 씁니다. 라벨은 공통 Entity 라벨이 아닌 것을 씁니다.
+func entityFromNode(node neo4j.Node) types.Entity {
+	entity := types.Entity{ID: node.ElementId, Properties: node.Props}
+	if id, ok := node.Props["entityId"].(string); ok && id != "" {
+		entity.ID = id
+	}
+	entity.Name, _ = node.Props["name"].(string)
+	entity.Aliases = stringsFromAny(node.Props["aliases"])
+	for _, label := range node.Labels {
+		if label != entityLabel {
+			entity.Label = label
+			break
+		}
+	}
+	if entity.Label == "" && len(node.Labels) > 0 {
+		entity.Label = node.Labels[0]
+	}
+	return entity
+}
+
+// chunkProperties 는 Chunk 노드에 저장하는 속성 맵입니다.
+func chunkProperties(chunk types.SourceChunk) map[string]any {
+	return map[string]any{
+		"chunkId":    chunk.ID,
+		"documentId": chunk.DocumentID,
+		"source":     chunk.Source,
+		"index":      int64(chunk.Index),
+		"heading":    chunk.Heading,
+		"text":       chunk.Text,
+		"startLine":  int64(chunk.StartLine),
+		"endLine":    int64(chunk.EndLine),
+		"page":       int64(chunk.Page),
+	}
+}
+
+func chunkFromNode(node neo4j.Node) types.SourceChunk {
+	chunk := types.SourceChunk{}
+	chunk.ID, _ = node.Props["chunkId"].(string)
+	chunk.DocumentID, _ = node.Props["documentId"].(string)
+	chunk.Source, _ = node.Props["source"].(string)
+	chunk.Heading, _ = node.Props["heading"].(string)
+	chunk.Text, _ = node.Props["text"].(string)
+	index, _ := node.Props["index"].(int64)
+	startLine, _ := node.Props["startLine"].(int64)
+	endLine, _ := node.Props["endLine"].(int64)
+	page, _ := node.Props["page"].(int64)
+	chunk.Index, chunk.StartLine, chunk.EndLine, chunk.Page = int(index), int(startLine), int(endLine), int(page)
+	return chunk
+}
+
+// cypherName 은 라벨이나 관계 타입을 백틱으로 감싸 Cypher 에 넣습니다. 온톨로지 없이 모델이 만든 이름이나
+// 저장된 노드에서 읽은 라벨도 그대로 쿼리에 들어가므로, 안에 있는 백틱은 두 번 써서 이름 밖으로 나가지 못하게 합니다.
+func cypherName(name string) string {
+	return "`" + strings.ReplaceAll(nodeLabel(name), "`", "``") + "`"
+}
+
+// anyList 는 문자열 목록을 드라이버 파라미터로 넘길 수 있는 []any 로 바꿉니다. nil 이면 빈 목록입니다.
+func anyList(values []string) []any {
+	list := make([]any, len(values))
+	for i, value := range values {
+		list[i] = value
+	}
+	return list
+}
+
+// subgraphFromPathRecords 는 nodes, rels 두 목록을 반환하는 레코드를 서브그래프로 바꿉니다.
+func subgraphFromPathRecords(records []*neo4j.Record) *types.Subgraph {
+	builder := newSubgraphBuilder()
+	if len(records) == 0 {
+		return builder.build()
+	}
+	record := records[0]
+
+	nodesByElementID := map[string]types.Entity{}
+	if nodesInterface, ok := record.Get("nodes"); ok && nodesInterface != nil {
+		for _, nodeInterface := range nodesInterface.([]any) {
+			node := nodeInterface.(neo4j.Node)
+			entity := entityFromNode(node)
+			nodesByElementID[node.ElementId] = entity
+			builder.addEntity(entity)
+		}
+	}
+
+	if relationsInterface, ok := record.Get("rels"); ok && relationsInterface != nil {
+		for _, relInterface := range relationsInterface.([]any) {
+			rel := relInterface.(neo4j.Relationship)
+			startNode, startOK := nodesByElementID[rel.StartElementId]
+			endNode, endOK := nodesByElementID[rel.EndElementId]
+			if startOK && endOK {
+				builder.addRelation(rel.ElementId, types.Relation{
+					SourceName: startNode.Name,
+					TargetName: endNode.Name,
+					Type:       rel.Type,
+					Sources:    stringsFromAny(rel.Props["sources"]),
+				})
+			}
+		}
+	}
+	return builder.build()
+}
@@ -0,0 +1,42 @@
+package db
+
+import "testing"
+
+func TestCypherName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "Person", want: "`Person`"},
+		{name: "Non Player", want: "`Non_Player`"},
+		{name: "인물", want: "`인물`"},
+		{name: "Person) DETACH DELETE (n", want: "`Person)_DETACH_DELETE_(n`"},
+		{name: "A` SET e.hacked = true //", want: "`A``_SET_e.hacked_=_true_//`"},
+	}
+
+	for _, tt := range tests {
+		if got := cypherName(tt.name); got != tt.want {
+			t.Errorf("cypherName(%q) = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRelabelClause(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing []string
+		label    string
+		want     string
+	}{
+		{name: "새 노드", label: "Person", want: "SET e:`Person`\n"},
+		{name: "같은 라벨", existing: []string{"Entity", "Person"}, label: "Person", want: "SET e:`Person`\n"},
+		{name: "이전 라벨 제거", existing: []string{"Concept", "Entity"}, label: "Person", want: "REMOVE e:`Concept`\nSET e:`Person`\n"},
+		{name: "공통 라벨만", existing: []string{"Entity", "Concept"}, label: "Entity", want: "REMOVE e:`Concept`\n"},
+	}
+
+	for _, tt := range tests {
+		if got := relabelClause(tt.existing, tt.label); got != tt.want {
+			t.Errorf("%s: relabelClause() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
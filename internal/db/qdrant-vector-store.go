@@ -0,0 +1,186 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"github.com/qdrant/go-client/qdrant"
+	"google.golang.org/grpc"
+)
+
+// QdrantVectorStore 는 Qdrant gRPC API 로 벡터를 저장하고 검색합니다.
+type QdrantVectorStore struct {
+	collections qdrant.CollectionsClient
+	points      qdrant.PointsClient
+	conn        *grpc.ClientConn
+}
+
+func NewQdrantVectorStore(collections qdrant.CollectionsClient, points qdrant.PointsClient, conn *grpc.ClientConn) *QdrantVectorStore {
+	return &QdrantVectorStore{collections: collections, points: points, conn: conn}
+}
+
+func (s *QdrantVectorStore) CreateCollection(ctx context.Context, collection string, dimension int) error {
+	exists, err := s.exists(ctx, collection)
+	if err != nil || exists {
+		return err
+	}
+
+	_, err = s.collections.Create(ctx, &qdrant.CreateCollection{
+		CollectionName: collection,
+		VectorsConfig: &qdrant.VectorsConfig{Config: &qdrant.VectorsConfig_Params{
+			Params: &qdrant.VectorParams{Size: uint64(dimension), Distance: qdrant.Distance_Cosine},
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("Qdrant 컬렉션 생성 실패 (%s): %w", collection, err)
+	}
+	return nil
+}
+
+func (s *QdrantVectorStore) DeleteCollection(ctx context.Context, collection string) error {
+	exists, err := s.exists(ctx, collection)
+	if err != nil || !exists {
+		return err
+	}
+
+	if _, err := s.collections.Delete(ctx, &qdrant.DeleteCollection{CollectionName: collection}); err != nil {
+		return fmt.Errorf("Qdrant 컬렉션 삭제 실패 (%s): %w", collection, err)
+	}
+	return nil
+}
+
+func (s *QdrantVectorStore) Upsert(ctx context.Context, collection string, points []VectorPoint) error {
+	if len(points) == 0 {
+		return nil
+	}
+
+	qdrantPoints := make([]*qdrant.PointStruct, 0, len(points))
+	for _, point := range points {
+		payload, err := qdrant.TryValueMap(point.Payload)
+		if err != nil {
+			return fmt.Errorf("Qdrant payload 변환 실패 (%s): %w", point.ID, err)
+		}
+		qdrantPoints = append(qdrantPoints, &qdrant.PointStruct{
+			Id:      qdrant.NewIDUUID(point.ID),
+			Vectors: qdrant.NewVectors(point.Vector...),
+			Payload: payload,
+		})
+	}
+
+	isWaitOption := true
+	_, err := s.points.Upsert(ctx, &qdrant.UpsertPoints{
+		CollectionName: collection, Wait: &isWaitOption,
+		Points: qdrantPoints,
+	})
+	if err != nil {
+		return fmt.Errorf("Quadrant 포인트 배치 업서트 실패 (%d개): %w", len(points), err)
+	}
+	return nil
+}
+
+func (s *QdrantVectorStore) Delete(ctx context.Context, collection string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	pointIDs := make([]*qdrant.PointId, len(ids))
+	for i, id := range ids {
+		pointIDs[i] = qdrant.NewIDUUID(id)
+	}
+
+	isWaitOption := true
+	_, err := s.points.Delete(ctx, &qdrant.DeletePoints{
+		CollectionName: collection, Wait: &isWaitOption,
+		Points: qdrant.NewPointsSelector(pointIDs...),
+	})
+	if err != nil {
+		return fmt.Errorf("Qdrant 포인트 삭제 실패 (%d개): %w", len(ids), err)
+	}
+	return nil
+}
+
+func (s *QdrantVectorStore) Search(ctx context.Context, collection string, vector []float32, topK int, filter VectorFilter) ([]VectorMatch, error) {
+	searchResult, err := s.points.Search(ctx, &qdrant.SearchPoints{
+		CollectionName: collection,
+		Vector:         vector,
+		Limit:          uint64(topK),
+		Filter:         qdrantFilter(filter),
+		WithPayload:    &qdrant.WithPayloadSelector{SelectorOptions: &qdrant.WithPayloadSelector_Enable{Enable: true}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Qdrant 벡터 검색 실패: %w", err)
+	}
+
+	matches := make([]VectorMatch, 0, len(searchResult.GetResult()))
+	for _, point := range searchResult.GetResult() {
+		payload := make(map[string]any, len(point.GetPayload()))
+		for key, value := range point.GetPayload() {
+			payload[key] = valueFromQdrant(value)
+		}
+		matches = append(matches, VectorMatch{ID: point.GetId().GetUuid(), Score: point.GetScore(), Payload: payload})
+	}
+	return matches, nil
+}
+
+func (s *QdrantVectorStore) Count(ctx context.Context, collection string, filter VectorFilter) (int, error) {
+	exact := true
+	resp, err := s.points.Count(ctx, &qdrant.CountPoints{
+		CollectionName: collection,
+		Filter:         qdrantFilter(filter),
+		Exact:          &exact,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("Qdrant 포인트 개수 조회 실패: %w", err)
+	}
+	return int(resp.GetResult().GetCount()), nil
+}
+
+func (s *QdrantVectorStore) Close() error {
+	return s.conn.Close()
+}
+
+func (s *QdrantVectorStore) exists(ctx context.Context, collection string) (bool, error) {
+	resp, err := s.collections.CollectionExists(ctx, &qdrant.CollectionExistsRequest{CollectionName: collection})
+	if err != nil {
+		return false, fmt.Errorf("Qdrant 컬렉션 확인 실패 (%s): %w", collection, err)
+	}
+	return resp.GetResult().GetExists(), nil
+}
+
+func qdrantFilter(filter VectorFilter) *qdrant.Filter {
+	if len(filter) == 0 {
+		return nil
+	}
+	conditions := make([]*qdrant.Condition, 0, len(filter))
+	for field, value := range filter {
+		conditions = append(conditions, qdrant.NewMatchKeyword(field, value))
+	}
+	return &qdrant.Filter{Must: conditions}
+}
+
+// valueFromQdrant 는 payload 값을 VectorPoint 에서 쓰는 Go 값으로 되돌립니다.
+func valueFromQdrant(value *qdrant.Value) any {
+	switch kind := value.GetKind().(type) {
+	case *qdrant.Value_StringValue:
+		return kind.StringValue
+	case *qdrant.Value_BoolValue:
+		return kind.BoolValue
+	case *qdrant.Value_IntegerValue:
+		return kind.IntegerValue
+	case *qdrant.Value_DoubleValue:
+		return kind.DoubleValue
+	case *qdrant.Value_ListValue:
+		values := make([]any, 0, len(kind.ListValue.GetValues()))
+		for _, item := range kind.ListValue.GetValues() {
+			values = append(values, valueFromQdrant(item))
+		}
+		return values
+	case *qdrant.Value_StructValue:
+		fields := make(map[string]any, len(kind.StructValue.GetFields()))
+		for key, item := range kind.StructValue.GetFields() {
+			fields[key] = valueFromQdrant(item)
+		}
+		return fields
+	default:
+		return nil
+	}
+}
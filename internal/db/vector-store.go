@@ -0,0 +1,49 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"github.com/JCSong-89/trpg-rag-game/pkg/types"
+)
+
+// VectorPoint 는 벡터 저장소에 저장하는 포인트입니다.
+// Payload 값은 string, bool, 정수, 실수와 이들의 []any 목록만 사용합니다.
+type VectorPoint struct {
+	ID      string
+	Vector  []float32
+	Payload map[string]any
+}
+
+type VectorMatch struct {
+	ID      string
+	Score   float32
+	Payload map[string]any
+}
+
+// VectorFilter 는 payload 필드가 주어진 값과 정확히 같은 포인트만 남깁니다. 필드가 목록이면 원소 중 하나만 같아도 됩니다.
+// 여러 필드를 주면 모두 만족해야 합니다.
+type VectorFilter map[string]string
+
+// VectorStore 는 임베딩 벡터 저장소의 공통 인터페이스입니다. 유사도는 코사인 유사도입니다.
+type VectorStore interface {
+	// CreateCollection 은 컬렉션이 없을 때만 만듭니다.
+	CreateCollection(ctx context.Context, collection string, dimension int) error
+	DeleteCollection(ctx context.Context, collection string) error
+	Upsert(ctx context.Context, collection string, points []VectorPoint) error
+	Delete(ctx context.Context, collection string, ids []string) error
+	Search(ctx context.Context, collection string, vector []float32, topK int, filter VectorFilter) ([]VectorMatch, error)
+	Count(ctx context.Context, collection string, filter VectorFilter) (int, error)
+	Close() error
+}
+
+func NewVectorStore(cfg types.Config) (VectorStore, error) {
+	switch cfg.Db.VectorStore {
+	case "", "qdrant":
+		collectionsClient, pointsClient, conn := NewQuadrantClient(cfg)
+		return NewQdrantVectorStore(collectionsClient, pointsClient, conn), nil
+	case "memory":
+		return NewMemoryVectorStore(cfg.Db.VectorStoreDir)
+	default:
+		return nil, fmt.Errorf("지원하지 않는 벡터 저장소입니다: %s", cfg.Db.VectorStore)
+	}
+}
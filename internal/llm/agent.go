@@ -0,0 +1,104 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+)
+
+const defaultAgentMaxSteps = 6
+
+const agentFinalPrompt = `You have reached the tool call limit. Do not call any more tools.
+Answer the original question now, using only the tool results and context above.`
+
+// ToolInvocation 은 에이전트가 실행한 도구 호출 하나의 기록입니다. Err 가 비어 있지 않으면 실패한 호출입니다.
+type ToolInvocation struct {
+	Step      int
+	Name      string
+	Arguments json.RawMessage
+	Result    string
+	Err       string
+	Duration  time.Duration
+}
+
+// AgentResult 의 Transcript 는 실행 순서대로 모든 도구 호출을 담습니다.
+type AgentResult struct {
+	Text       string
+	Model      string
+	Steps      int
+	Transcript []ToolInvocation
+}
+
+// RunAgent 는 모델이 더 이상 도구를 호출하지 않을 때까지 도구 실행과 재호출을 반복합니다.
+// 도구 오류는 중단하지 않고 {"error": ...} 결과로 모델에게 돌려줍니다.
+// maxSteps 번 도구를 호출하고도 끝나지 않으면 도구 호출을 막고 한 번 더 불러 답변을 받습니다.
+func RunAgent(ctx context.Context, provider Provider, registry *ToolRegistry, prompt string, maxSteps int, opts ...Option) (*AgentResult, error) {
+	if maxSteps <= 0 {
+		maxSteps = defaultAgentMaxSteps
+	}
+
+	history := append([]Message(nil), resolveOptions(GenerateOptions{}, opts).History...)
+	result := &AgentResult{}
+	currentPrompt := prompt
+
+	for step := 1; ; step++ {
+		limitReached := step > maxSteps
+		if limitReached {
+			currentPrompt = agentFinalPrompt
+		}
+
+		callOptions := append(append([]Option(nil), opts...), WithHistory(history), WithTools(registry.Tools()...))
+		if limitReached {
+			callOptions = append(callOptions, WithToolMode(ToolModeNone))
+		}
+
+		resp, err := provider.Generate(ctx, currentPrompt, callOptions...)
+		if err != nil {
+			return nil, fmt.Errorf("에이전트 %d단계 호출 실패: %w", step, err)
+		}
+		result.Steps = step
+		result.Model = resp.Model
+
+		if len(resp.ToolCalls) == 0 || limitReached {
+			if limitReached && len(resp.ToolCalls) > 0 {
+				log.Printf("경고: 도구 호출 한도(%d단계)에 도달했지만 모델이 도구를 다시 요청했습니다. 텍스트 응답만 사용합니다.", maxSteps)
+			}
+			result.Text = resp.Text
+			return result, nil
+		}
+
+		if currentPrompt != "" {
+			history = append(history, Message{Role: "user", Content: currentPrompt})
+		}
+		history = append(history, Message{Role: "assistant", Content: resp.Text, ToolCalls: resp.ToolCalls})
+
+		for _, call := range resp.ToolCalls {
+			started := time.Now()
+			output, callErr := registry.Call(ctx, call)
+			invocation := ToolInvocation{
+				Step:      step,
+				Name:      call.Name,
+				Arguments: call.Arguments,
+				Result:    output,
+				Duration:  time.Since(started),
+			}
+			if callErr != nil {
+				if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
+				invocation.Err = callErr.Error()
+				encoded, _ := json.Marshal(map[string]string{"error": callErr.Error()})
+				output = string(encoded)
+				log.Printf("경고: 도구 호출 실패 [%d단계] %s(%s): %v", step, call.Name, call.Arguments, callErr)
+			} else {
+				log.Printf("도구 호출 [%d단계] %s(%s) 완료 (%d바이트, %v)", step, call.Name, call.Arguments, len(output), invocation.Duration)
+			}
+
+			result.Transcript = append(result.Transcript, invocation)
+			history = append(history, Message{Role: "tool", Name: call.Name, ToolCallID: call.ID, Content: output})
+		}
+		currentPrompt = ""
+	}
+}
@@ -2,15 +2,50 @@ package llm
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/JCSong-89/trpg-rag-game/pkg/types"
 	"net/http"
 )
 
-const bgeAPIURL = "https://router.huggingface.co/hf-inference/models/BAAI/bge-m3/pipeline/feature-extraction"
+const (
+	bgeAPIURL       = "https://router.huggingface.co/hf-inference/models/BAAI/bge-m3/pipeline/feature-extraction"
+	bgeModelID      = "BAAI/bge-m3"
+	bgeM3Dimensions = 1024
+)
 
-func GetBGEEmbeddings(texts []string, apiToken string) ([][]float32, error) {
+// BGEEmbedder 는 Hugging Face feature-extraction 파이프라인으로 BGE-M3 임베딩을 생성합니다.
+type BGEEmbedder struct {
+	url      string
+	apiToken string
+	client   *http.Client
+}
+
+func NewBGEEmbedder(cfg types.EmbeddingConfig) (*BGEEmbedder, error) {
+	if cfg.APIKey == "" && requiresAPIKey(cfg.HTTP) {
+		return nil, fmt.Errorf("HUGGING_TOKEN 환경 변수를 설정해주세요")
+	}
+	url := cfg.BaseURL
+	if url == "" {
+		url = bgeAPIURL
+	}
+	client, err := NewHTTPClient("huggingface", cfg.HTTP)
+	if err != nil {
+		return nil, err
+	}
+	return &BGEEmbedder{url: url, apiToken: cfg.APIKey, client: client}, nil
+}
+
+func (e *BGEEmbedder) Model() string {
+	return bgeModelID
+}
+
+func (e *BGEEmbedder) Dimension() int {
+	return bgeM3Dimensions
+}
+
+func (e *BGEEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
 	reqBody := types.EmbeddingRequest{
 		Inputs: texts,
 	}
@@ -20,16 +55,15 @@ func GetBGEEmbeddings(texts []string, apiToken string) ([][]float32, error) {
 		return nil, fmt.Errorf("JSON 인코딩 실패: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", bgeAPIURL, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", e.url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, fmt.Errorf("HTTP 요청 생성 실패: %w", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+apiToken)
+	req.Header.Set("Authorization", "Bearer "+e.apiToken)
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := e.client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("Hugging Face API 호출 실패: %w", err)
 	}
@@ -47,4 +81,13 @@ func GetBGEEmbeddings(texts []string, apiToken string) ([][]float32, error) {
 	}
 
 	return embeddings, nil
-}
\ No newline at end of file
+}
+
+// GetBGEEmbeddings 는 기본 Hugging Face 엔드포인트로 임베딩을 요청하는 기존 진입점입니다.
+func GetBGEEmbeddings(texts []string, apiToken string) ([][]float32, error) {
+	embedder, err := NewBGEEmbedder(types.EmbeddingConfig{APIKey: apiToken})
+	if err != nil {
+		return nil, err
+	}
+	return embedder.Embed(context.Background(), texts)
+}
@@ -0,0 +1,138 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"github.com/JCSong-89/trpg-rag-game/internal/prompt"
+	"github.com/JCSong-89/trpg-rag-game/pkg/types"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	defaultConversationTokenBudget  = 4000
+	defaultConversationKeepMessages = 4
+
+	// 토큰 수는 프로바이더마다 다르므로 룬 3개를 1토큰으로 어림합니다.
+	runesPerToken = 3
+)
+
+// Conversation 은 역할이 붙은 대화 턴을 보관하고, 다음 호출에 넘길 이력을 만들어 줍니다.
+// 이력이 토큰 예산을 넘으면 오래된 턴을 요약 하나로 접습니다. 여러 고루틴에서 동시에 쓰지 않습니다.
+type Conversation struct {
+	provider     Provider
+	tokenBudget  int
+	keepMessages int
+
+	summary string
+	turns   []Message
+}
+
+func NewConversation(provider Provider, cfg types.ConversationConfig) *Conversation {
+	tokenBudget := cfg.TokenBudget
+	if tokenBudget <= 0 {
+		tokenBudget = defaultConversationTokenBudget
+	}
+	keepMessages := cfg.KeepMessages
+	if keepMessages < 0 {
+		keepMessages = defaultConversationKeepMessages
+	}
+	return &Conversation{provider: provider, tokenBudget: tokenBudget, keepMessages: keepMessages}
+}
+
+// AddExchange 는 사용자 질문과 그에 대한 답변을 한 쌍으로 기록합니다.
+// 검색 컨텍스트가 붙은 최종 프롬프트가 아니라 사용자가 실제로 입력한 질문을 넘겨야 이력이 불어나지 않습니다.
+func (c *Conversation) AddExchange(question string, answer string) {
+	c.turns = append(c.turns,
+		Message{Role: "user", Content: question},
+		Message{Role: "assistant", Content: answer},
+	)
+}
+
+// History 는 요약(있다면)과 남아 있는 턴을 WithHistory 에 넘길 수 있는 형태로 돌려줍니다.
+// 요약은 역할이 번갈아 나오도록 사용자/어시스턴트 한 쌍으로 앞에 붙입니다.
+func (c *Conversation) History() []Message {
+	history := make([]Message, 0, len(c.turns)+2)
+	if c.summary != "" {
+		history = append(history,
+			Message{Role: "user", Content: "지금까지의 대화 요약:\n" + c.summary},
+			Message{Role: "assistant", Content: "네, 요약된 내용을 기억하고 이어서 답변하겠습니다."},
+		)
+	}
+	return append(history, c.turns...)
+}
+
+func (c *Conversation) Len() int {
+	return len(c.turns)
+}
+
+// Compact 는 이력이 토큰 예산을 넘으면 최근 keepMessages 개를 제외한 턴을 기존 요약과 합쳐 다시 요약합니다.
+// 요약에 실패하면 턴을 그대로 두고 오류를 돌려줍니다.
+func (c *Conversation) Compact(ctx context.Context) error {
+	if estimateTokens(c.History()) <= c.tokenBudget {
+		return nil
+	}
+
+	cut := len(c.turns) - c.keepMessages
+	// 남는 이력이 어시스턴트 답변으로 시작하지 않도록 질문/답변 쌍 단위로 자릅니다.
+	if cut > 0 && cut < len(c.turns) && c.turns[cut].Role == "assistant" {
+		cut++
+	}
+	if cut <= 0 {
+		return nil
+	}
+
+	summaryPrompt := fmt.Sprintf(prompt.ConversationSummaryPromptTemplate, c.summary, formatTurns(c.turns[:cut]))
+	resp, err := c.provider.Generate(ctx, summaryPrompt, WithStage(StageSummary))
+	if err != nil {
+		return fmt.Errorf("대화 요약 실패: %w", err)
+	}
+
+	c.summary = strings.TrimSpace(resp.Text)
+	c.turns = append([]Message(nil), c.turns[cut:]...)
+	return nil
+}
+
+type standaloneQuery struct {
+	Query string `json:"query"`
+}
+
+// ResolveFollowUp 은 "그 팀은요?" 같은 후속 질문을 이전 대화를 참고해 단독으로 이해되는 질문으로 바꿉니다.
+// 검색은 재작성된 질문으로 해야 대명사가 가리키는 엔티티를 찾을 수 있습니다. 이전 대화가 없으면 그대로 돌려줍니다.
+func (c *Conversation) ResolveFollowUp(ctx context.Context, question string) (string, error) {
+	history := c.History()
+	if len(history) == 0 {
+		return question, nil
+	}
+
+	rewritePrompt := fmt.Sprintf(prompt.FollowUpRewritePromptTemplate, formatTurns(history), question)
+	var rewritten standaloneQuery
+	if _, err := GenerateJSON(ctx, c.provider, rewritePrompt, &rewritten, WithStage(StageFollowUp)); err != nil {
+		return "", fmt.Errorf("후속 질문 재작성 실패: %w", err)
+	}
+
+	query := strings.TrimSpace(rewritten.Query)
+	if query == "" {
+		return question, nil
+	}
+	return query, nil
+}
+
+func formatTurns(messages []Message) string {
+	var sb strings.Builder
+	for _, m := range messages {
+		sb.WriteString(m.Role)
+		sb.WriteString(": ")
+		sb.WriteString(m.Content)
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+func estimateTokens(messages []Message) int {
+	runes := 0
+	for _, m := range messages {
+		runes += utf8.RuneCountInString(m.Content)
+	}
+	return (runes + runesPerToken - 1) / runesPerToken
+}
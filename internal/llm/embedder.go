@@ -0,0 +1,28 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"github.com/JCSong-89/trpg-rag-game/pkg/types"
+)
+
+// Embedder 는 텍스트를 고정 차원의 벡터로 바꾸는 임베딩 백엔드의 공통 인터페이스입니다.
+// Dimension 은 Qdrant 컬렉션 생성 시 벡터 크기로 사용됩니다.
+type Embedder interface {
+	Model() string
+	Dimension() int
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+func NewEmbedder(cfg types.EmbeddingConfig) (Embedder, error) {
+	switch cfg.Provider {
+	case "", "bge":
+		return NewBGEEmbedder(cfg)
+	case "openai", "local":
+		return NewOpenAIEmbedder(cfg)
+	case "hash":
+		return NewHashEmbedder(cfg.Dimension), nil
+	default:
+		return nil, fmt.Errorf("지원하지 않는 임베딩 프로바이더입니다: %s", cfg.Provider)
+	}
+}
@@ -0,0 +1,222 @@
+package llm
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// maxCachedDimension 은 손상된 레코드로 거대한 버퍼를 잡지 않기 위한 상한입니다.
+const maxCachedDimension = 1 << 16
+
+type cacheKey [sha256.Size]byte
+
+type EmbeddingCacheStats struct {
+	Hits    int64
+	Misses  int64
+	Entries int
+}
+
+// CachedEmbedder 는 (모델 ID, 텍스트) 해시를 키로 임베딩을 디스크에 저장해 두고
+// 같은 입력이 다시 들어오면 원본 임베더를 호출하지 않고 저장된 벡터를 돌려줍니다.
+//
+// 파일은 [32바이트 키][uint32 차원][float32 * 차원] 레코드를 이어 붙인 추가 전용 형식입니다.
+// 중간에 끊긴 마지막 레코드는 다음 로드 때 버려집니다.
+type CachedEmbedder struct {
+	inner Embedder
+
+	mu      sync.RWMutex
+	entries map[cacheKey][]float32
+	file    *os.File
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+func NewCachedEmbedder(inner Embedder, path string) (*CachedEmbedder, error) {
+	entries, validSize, err := loadEmbeddingCache(path)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("임베딩 캐시 파일 열기 실패 (%s): %w", path, err)
+	}
+	if err := file.Truncate(validSize); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("임베딩 캐시 파일 정리 실패 (%s): %w", path, err)
+	}
+	if _, err := file.Seek(validSize, io.SeekStart); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("임베딩 캐시 파일 이동 실패 (%s): %w", path, err)
+	}
+
+	return &CachedEmbedder{inner: inner, entries: entries, file: file}, nil
+}
+
+func (c *CachedEmbedder) Model() string {
+	return c.inner.Model()
+}
+
+func (c *CachedEmbedder) Dimension() int {
+	return c.inner.Dimension()
+}
+
+func (c *CachedEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	embeddings := make([][]float32, len(texts))
+	keys := make([]cacheKey, len(texts))
+
+	var missTexts []string
+	var missIndexes []int
+
+	c.mu.RLock()
+	for i, text := range texts {
+		keys[i] = c.key(text)
+		if vector, ok := c.entries[keys[i]]; ok && c.fits(vector) {
+			embeddings[i] = vector
+			continue
+		}
+		missTexts = append(missTexts, text)
+		missIndexes = append(missIndexes, i)
+	}
+	c.mu.RUnlock()
+
+	c.hits.Add(int64(len(texts) - len(missTexts)))
+	c.misses.Add(int64(len(missTexts)))
+
+	if len(missTexts) == 0 {
+		return embeddings, nil
+	}
+
+	fresh, err := c.inner.Embed(ctx, missTexts)
+	if err != nil {
+		return nil, err
+	}
+	if len(fresh) != len(missTexts) {
+		return nil, fmt.Errorf("임베딩 개수가 입력과 다릅니다 (입력: %d, 응답: %d)", len(missTexts), len(fresh))
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	writer := bufio.NewWriter(c.file)
+	for j, i := range missIndexes {
+		embeddings[i] = fresh[j]
+		if vector, exists := c.entries[keys[i]]; exists && c.fits(vector) {
+			continue
+		}
+		c.entries[keys[i]] = fresh[j]
+		if err := writeCacheRecord(writer, keys[i], fresh[j]); err != nil {
+			return nil, fmt.Errorf("임베딩 캐시 기록 실패: %w", err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return nil, fmt.Errorf("임베딩 캐시 기록 실패: %w", err)
+	}
+
+	return embeddings, nil
+}
+
+func (c *CachedEmbedder) Stats() EmbeddingCacheStats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return EmbeddingCacheStats{
+		Hits:    c.hits.Load(),
+		Misses:  c.misses.Load(),
+		Entries: len(c.entries),
+	}
+}
+
+func (c *CachedEmbedder) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.file.Close()
+}
+
+// key 는 모델과 차원, 텍스트로 만듭니다. 같은 모델이라도 차원 설정이 바뀌면 다른 키가 됩니다.
+func (c *CachedEmbedder) key(text string) cacheKey {
+	h := sha256.New()
+	h.Write([]byte(c.inner.Model()))
+	h.Write([]byte{0})
+	h.Write([]byte(strconv.Itoa(c.inner.Dimension())))
+	h.Write([]byte{0})
+	h.Write([]byte(text))
+
+	var key cacheKey
+	copy(key[:], h.Sum(nil))
+	return key
+}
+
+// fits 는 캐시된 벡터의 길이가 임베더의 차원과 맞는지 확인합니다. 차원을 모르는 임베더(0)는 검사하지 않습니다.
+func (c *CachedEmbedder) fits(vector []float32) bool {
+	dimension := c.inner.Dimension()
+	return dimension <= 0 || len(vector) == dimension
+}
+
+func writeCacheRecord(w io.Writer, key cacheKey, vector []float32) error {
+	if _, err := w.Write(key[:]); err != nil {
+		return err
+	}
+	buf := make([]byte, 4+4*len(vector))
+	binary.LittleEndian.PutUint32(buf, uint32(len(vector)))
+	for i, v := range vector {
+		binary.LittleEndian.PutUint32(buf[4+4*i:], math.Float32bits(v))
+	}
+	_, err := w.Write(buf)
+	return err
+}
+
+// loadEmbeddingCache 는 캐시 파일을 읽어 메모리 맵과 온전한 레코드까지의 바이트 수를 돌려줍니다.
+func loadEmbeddingCache(path string) (map[cacheKey][]float32, int64, error) {
+	entries := make(map[cacheKey][]float32)
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return entries, 0, nil
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("임베딩 캐시 파일 읽기 실패 (%s): %w", path, err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	var validSize int64
+	for {
+		var key cacheKey
+		if _, err := io.ReadFull(reader, key[:]); err != nil {
+			break
+		}
+
+		var header [4]byte
+		if _, err := io.ReadFull(reader, header[:]); err != nil {
+			break
+		}
+		dimension := binary.LittleEndian.Uint32(header[:])
+		if dimension > maxCachedDimension {
+			break
+		}
+
+		buf := make([]byte, 4*int(dimension))
+		if _, err := io.ReadFull(reader, buf); err != nil {
+			break
+		}
+		vector := make([]float32, dimension)
+		for i := range vector {
+			vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:]))
+		}
+
+		entries[key] = vector
+		validSize += int64(len(key) + len(header) + len(buf))
+	}
+
+	return entries, validSize, nil
+}
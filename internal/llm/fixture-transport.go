@@ -0,0 +1,218 @@
+package llm
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/JCSong-89/trpg-rag-game/pkg/types"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	FixtureModeRecord = "record"
+	FixtureModeReplay = "replay"
+)
+
+// fixture 는 요청/응답 한 쌍을 저장하는 파일 형식입니다. 인증 헤더는 저장하지 않습니다.
+type fixture struct {
+	Request struct {
+		Method string          `json:"method"`
+		URL    string          `json:"url"`
+		Body   json.RawMessage `json:"body,omitempty"`
+	} `json:"request"`
+	Response struct {
+		StatusCode  int    `json:"statusCode"`
+		ContentType string `json:"contentType,omitempty"`
+		Body        string `json:"body"`
+	} `json:"response"`
+}
+
+// fixtureTransport 는 record 모드에서 실제 호출의 요청/응답을 파일로 남기고,
+// replay 모드에서는 네트워크 없이 저장된 응답을 돌려줍니다.
+// 요청은 메서드, URL 경로, 정규화한 본문의 해시로 찾습니다.
+type fixtureTransport struct {
+	mode string
+	dir  string
+	next http.RoundTripper
+}
+
+// requiresAPIKey 는 생성자가 API 키를 요구해야 하는지입니다. replay 모드는 네트워크를 쓰지 않으므로 키가 없어도 됩니다.
+func requiresAPIKey(cfg types.HTTPConfig) bool {
+	return cfg.FixtureMode != FixtureModeReplay
+}
+
+func newFixtureTransport(mode string, dir string, next http.RoundTripper) (http.RoundTripper, error) {
+	switch mode {
+	case "":
+		return next, nil
+	case FixtureModeRecord, FixtureModeReplay:
+	default:
+		return nil, fmt.Errorf("지원하지 않는 FIXTURE_MODE 입니다: %s", mode)
+	}
+	if dir == "" {
+		return nil, fmt.Errorf("FIXTURE_DIR 환경 변수를 설정해주세요")
+	}
+	if mode == FixtureModeRecord {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("픽스처 디렉터리 생성 실패 (%s): %w", dir, err)
+		}
+	}
+	return &fixtureTransport{mode: mode, dir: dir, next: next}, nil
+}
+
+func (t *fixtureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("요청 본문 읽기 실패: %w", err)
+		}
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(body)), nil }
+	}
+
+	normalizedBody := normalizeFixtureBody(body)
+	key := fixtureKey(req, normalizedBody)
+	path := filepath.Join(t.dir, key+".json")
+
+	if t.mode == FixtureModeReplay {
+		return t.replay(req, path, key)
+	}
+	return t.record(req, path, normalizedBody)
+}
+
+func (t *fixtureTransport) replay(req *http.Request, path string, key string) (*http.Response, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("저장된 픽스처가 없습니다 (%s %s, 키: %s)", req.Method, fixtureURL(req), key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("픽스처 읽기 실패 (%s): %w", path, err)
+	}
+
+	var f fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("픽스처 파싱 실패 (%s): %w", path, err)
+	}
+
+	header := http.Header{}
+	if f.Response.ContentType != "" {
+		header.Set("Content-Type", f.Response.ContentType)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", f.Response.StatusCode, http.StatusText(f.Response.StatusCode)),
+		StatusCode:    f.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(f.Response.Body)),
+		ContentLength: int64(len(f.Response.Body)),
+		Request:       req,
+	}, nil
+}
+
+func (t *fixtureTransport) record(req *http.Request, path string, normalizedBody []byte) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("응답 본문 읽기 실패: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	// 일시적인 실패 응답은 재생 시 같은 실패를 고정시키므로 남기지 않습니다.
+	if isRetryableStatus(resp.StatusCode) {
+		return resp, nil
+	}
+
+	var f fixture
+	f.Request.Method = req.Method
+	f.Request.URL = fixtureURL(req)
+	if json.Valid(normalizedBody) {
+		f.Request.Body = normalizedBody
+	}
+	f.Response.StatusCode = resp.StatusCode
+	f.Response.ContentType = resp.Header.Get("Content-Type")
+	f.Response.Body = string(respBody)
+
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("픽스처 인코딩 실패: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return nil, fmt.Errorf("픽스처 저장 실패 (%s): %w", path, err)
+	}
+	return resp, nil
+}
+
+func fixtureURL(req *http.Request) string {
+	u := *req.URL
+	u.RawQuery = ""
+	u.User = nil
+	return u.String()
+}
+
+// fixtureKey 는 요청을 식별하는 해시입니다. 로컬 서버 포트가 바뀌어도 재생되도록 호스트는 빼고 경로만 쓰며,
+// 쿼리 문자열 중에서는 스트리밍 여부(alt=sse)만 구분합니다.
+func fixtureKey(req *http.Request, normalizedBody []byte) string {
+	h := sha256.New()
+	h.Write([]byte(req.Method))
+	h.Write([]byte{0})
+	h.Write([]byte(req.URL.Path))
+	h.Write([]byte{0})
+	h.Write([]byte(req.URL.Query().Get("alt")))
+	h.Write([]byte{0})
+	h.Write(normalizedBody)
+	return hex.EncodeToString(h.Sum(nil))[:32]
+}
+
+// normalizeFixtureBody 는 JSON 본문을 키 순서가 고정된 형태로 다시 쓰고 문자열의 공백을 정리해,
+// 프롬프트 템플릿의 들여쓰기나 줄바꿈 차이로 다른 픽스처가 되지 않게 합니다.
+func normalizeFixtureBody(body []byte) []byte {
+	if len(body) == 0 {
+		return nil
+	}
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return body
+	}
+	normalized, err := json.Marshal(normalizeFixtureValue(value))
+	if err != nil {
+		return body
+	}
+	return normalized
+}
+
+func normalizeFixtureValue(value any) any {
+	switch v := value.(type) {
+	case string:
+		return strings.Join(strings.Fields(v), " ")
+	case []any:
+		for i := range v {
+			v[i] = normalizeFixtureValue(v[i])
+		}
+		return v
+	case map[string]any:
+		for key := range v {
+			v[key] = normalizeFixtureValue(v[key])
+		}
+		return v
+	default:
+		return v
+	}
+}
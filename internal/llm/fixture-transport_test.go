@@ -0,0 +1,78 @@
+package llm
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/JCSong-89/trpg-rag-game/pkg/types"
+)
+
+// testdata/fixtures 의 응답은 FIXTURE_MODE=record 로 남긴 것이며, 재생에는 API 키나 네트워크가 필요 없습니다.
+var replayHTTPConfig = types.HTTPConfig{FixtureMode: FixtureModeReplay, FixtureDir: "testdata/fixtures"}
+
+type replayScene struct {
+	Location string   `json:"location"`
+	NPCs     []string `json:"npcs"`
+	Danger   int      `json:"danger"`
+}
+
+func TestReplayGenerateJSON(t *testing.T) {
+	provider, err := NewGeminiProvider(types.LLMConfig{HTTP: replayHTTPConfig})
+	if err != nil {
+		t.Fatalf("NewGeminiProvider() error = %v", err)
+	}
+
+	var scene replayScene
+	resp, err := GenerateJSON(context.Background(), provider, "Describe the tavern where the party meets the informant.", &scene, WithTemperature(0))
+	if err != nil {
+		t.Fatalf("GenerateJSON() error = %v", err)
+	}
+
+	want := replayScene{Location: "The Rusty Flagon", NPCs: []string{"Mira the barkeep", "Old Tobin"}, Danger: 2}
+	if scene.Location != want.Location || !slices.Equal(scene.NPCs, want.NPCs) || scene.Danger != want.Danger {
+		t.Errorf("scene = %+v, want %+v", scene, want)
+	}
+	if resp.Model != defaultGeminiModel || resp.Usage.TotalTokens == 0 {
+		t.Errorf("response model=%q usage=%+v, want model %q with usage", resp.Model, resp.Usage, defaultGeminiModel)
+	}
+}
+
+func TestReplayEmbed(t *testing.T) {
+	embedder, err := NewBGEEmbedder(types.EmbeddingConfig{HTTP: replayHTTPConfig})
+	if err != nil {
+		t.Fatalf("NewBGEEmbedder() error = %v", err)
+	}
+
+	vectors, err := embedder.Embed(context.Background(), []string{"The Rusty Flagon", "Mira the barkeep"})
+	if err != nil {
+		t.Fatalf("Embed() error = %v", err)
+	}
+	if len(vectors) != 2 {
+		t.Fatalf("Embed() returned %d vectors, want 2", len(vectors))
+	}
+	for i, vector := range vectors {
+		if len(vector) != embedder.Dimension() {
+			t.Errorf("vector %d has %d dimensions, want %d", i, len(vector), embedder.Dimension())
+		}
+	}
+}
+
+func TestReplayMissingFixture(t *testing.T) {
+	embedder, err := NewBGEEmbedder(types.EmbeddingConfig{HTTP: replayHTTPConfig})
+	if err != nil {
+		t.Fatalf("NewBGEEmbedder() error = %v", err)
+	}
+	if _, err := embedder.Embed(context.Background(), []string{"not recorded"}); err == nil {
+		t.Error("Embed() with no recorded fixture: want error")
+	}
+}
+
+func TestAPIKeyRequiredOutsideReplay(t *testing.T) {
+	if _, err := NewGeminiProvider(types.LLMConfig{}); err == nil {
+		t.Error("NewGeminiProvider() without key: want error")
+	}
+	if _, err := NewBGEEmbedder(types.EmbeddingConfig{}); err == nil {
+		t.Error("NewBGEEmbedder() without key: want error")
+	}
+}
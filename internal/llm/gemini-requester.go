@@ -9,68 +9,330 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"strings"
+	"sync"
 )
 
-const httpApiURL = "https://generativelanguage.googleapis.com/v1beta/models/gemini-2.0-flash:generateContent"
+const (
+	geminiBaseURL      = "https://generativelanguage.googleapis.com/v1beta"
+	defaultGeminiModel = "gemini-2.0-flash"
+)
 
-func GenerateContentWithHTTP(ctx context.Context, prompt string) (string, error) {
-	apiKey := os.Getenv("GEMINI_API_KEY")
-	if apiKey == "" {
-		return "", fmt.Errorf("GEMINI_API_KEY 환경 변수를 설정해주세요")
-	}
+// GeminiProvider 는 Gemini REST API 를 호출합니다. 하나의 HTTP 클라이언트를 모든 호출이 공유하므로
+// 프로바이더를 한 번 만들어 재사용해야 연결, 속도 제한, 서킷 브레이커 상태가 유지됩니다.
+type GeminiProvider struct {
+	apiKey         string
+	client         *http.Client
+	defaults       GenerateOptions
+	safetySettings []types.GeminiSafetySetting
+}
 
-	payload := types.GeminiHttpRequest{
-		Contents: []struct {
-			Parts []struct {
-				Text string `json:"text"`
-			} `json:"parts"`
-		}{
-			{
-				Parts: []struct {
-					Text string `json:"text"`
-				}{
-					{Text: prompt},
-				},
-			},
-		},
+func NewGeminiProvider(cfg types.LLMConfig) (*GeminiProvider, error) {
+	if cfg.APIKey == "" && requiresAPIKey(cfg.HTTP) {
+		return nil, fmt.Errorf("GEMINI_API_KEY(또는 GOOGLE_API_KEY) 환경 변수를 설정해주세요")
 	}
-	jsonData, err := json.Marshal(payload)
+	client, err := NewHTTPClient("gemini", cfg.HTTP)
 	if err != nil {
-		return "", fmt.Errorf("JSON 데이터 생성 실패: %v", err)
+		return nil, err
 	}
+	return &GeminiProvider{
+		apiKey:         cfg.APIKey,
+		client:         client,
+		defaults:       defaultOptions(cfg, defaultGeminiModel),
+		safetySettings: cfg.SafetySettings,
+	}, nil
+}
+
+func (p *GeminiProvider) Name() string {
+	return "gemini"
+}
+
+func (p *GeminiProvider) ResolveOptions(opts []Option) GenerateOptions {
+	return resolveOptions(p.defaults, opts)
+}
+
+func (p *GeminiProvider) Generate(ctx context.Context, prompt string, opts ...Option) (*Response, error) {
+	options := resolveOptions(p.defaults, opts)
 
-	req, err := http.NewRequestWithContext(ctx, "POST", httpApiURL, bytes.NewBuffer(jsonData))
+	req, err := p.newRequest(ctx, "generateContent", prompt, options)
 	if err != nil {
-		return "", fmt.Errorf("HTTP 요청 객체 생성 실패: %v", err)
+		return nil, err
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-goog-api-key", apiKey)
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := p.client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("API 요청 실행 실패: %v", err)
+		return nil, fmt.Errorf("API 요청 실행 실패: %v", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("API 응답 읽기 실패: %v", err)
+		return nil, fmt.Errorf("API 응답 읽기 실패: %v", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("API가 에러를 반환했습니다 (상태 코드: %d): %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("API가 에러를 반환했습니다 (상태 코드: %d): %s", resp.StatusCode, string(body))
 	}
 
 	var apiResponse types.GeminiHttpResponse
 	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		return "", fmt.Errorf("JSON 응답 파싱 실패: %v", err)
+		return nil, fmt.Errorf("JSON 응답 파싱 실패: %v", err)
+	}
+
+	return responseFromGemini(apiResponse, options.Model)
+}
+
+// responseFromGemini 는 첫 번째 후보의 텍스트 파트를 이어 붙이고 함수 호출 파트를 ToolCalls 로 옮깁니다.
+// 안전 설정으로 차단된 경우에는 차단 사유를 오류에 담습니다.
+func responseFromGemini(apiResponse types.GeminiHttpResponse, model string) (*Response, error) {
+	if len(apiResponse.Candidates) == 0 {
+		if apiResponse.PromptFeedback != nil && apiResponse.PromptFeedback.BlockReason != "" {
+			return nil, fmt.Errorf("프롬프트가 차단되었습니다 (사유: %s)", apiResponse.PromptFeedback.BlockReason)
+		}
+		return nil, fmt.Errorf("응답에서 텍스트를 찾을 수 없습니다")
+	}
+
+	candidate := apiResponse.Candidates[0]
+	response := &Response{Model: model, Usage: usageFromGemini(apiResponse.UsageMetadata)}
+	var text strings.Builder
+	for _, part := range candidate.Content.Parts {
+		text.WriteString(part.Text)
+		if part.FunctionCall != nil {
+			arguments := part.FunctionCall.Args
+			if len(arguments) == 0 {
+				arguments = json.RawMessage("{}")
+			}
+			response.ToolCalls = append(response.ToolCalls, ToolCall{
+				ID:        part.FunctionCall.ID,
+				Name:      part.FunctionCall.Name,
+				Arguments: arguments,
+			})
+		}
+	}
+	response.Text = text.String()
+
+	if response.Text == "" && len(response.ToolCalls) == 0 {
+		if candidate.FinishReason != "" && candidate.FinishReason != "STOP" {
+			return nil, fmt.Errorf("응답이 생성되지 않았습니다 (종료 사유: %s)", candidate.FinishReason)
+		}
+		return nil, fmt.Errorf("응답에서 텍스트를 찾을 수 없습니다")
+	}
+	return response, nil
+}
+
+// Stream 은 streamGenerateContent 의 SSE 응답을 읽어 생성되는 텍스트를 조각 단위로 보냅니다.
+func (p *GeminiProvider) Stream(ctx context.Context, prompt string, opts ...Option) (<-chan StreamChunk, error) {
+	options := resolveOptions(p.defaults, opts)
+
+	req, err := p.newRequest(ctx, "streamGenerateContent?alt=sse", prompt, options)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("API 요청 실행 실패: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API가 에러를 반환했습니다 (상태 코드: %d): %s", resp.StatusCode, string(body))
+	}
+
+	chunks := make(chan StreamChunk)
+	go func() {
+		defer close(chunks)
+		defer resp.Body.Close()
+
+		var usage *types.GeminiUsageMetadata
+		err := readServerSentEvents(resp.Body, func(data []byte) (bool, error) {
+			var event types.GeminiHttpResponse
+			if err := json.Unmarshal(data, &event); err != nil {
+				return false, fmt.Errorf("스트림 이벤트 파싱 실패: %v", err)
+			}
+			if event.UsageMetadata != nil {
+				usage = event.UsageMetadata
+			}
+			for _, candidate := range event.Candidates {
+				for _, part := range candidate.Content.Parts {
+					if part.Text == "" {
+						continue
+					}
+					if !sendChunk(ctx, chunks, StreamChunk{Text: part.Text}) {
+						return false, nil
+					}
+				}
+			}
+			return true, nil
+		})
+		if err != nil {
+			if ctx.Err() == nil {
+				sendChunk(ctx, chunks, StreamChunk{Err: err})
+			}
+			return
+		}
+		if usage != nil {
+			final := usageFromGemini(usage)
+			sendChunk(ctx, chunks, StreamChunk{Usage: &final, Model: options.Model})
+		}
+	}()
+	return chunks, nil
+}
+
+func (p *GeminiProvider) newRequest(ctx context.Context, method string, prompt string, options GenerateOptions) (*http.Request, error) {
+	payload := types.GeminiHttpRequest{
+		Contents:          buildGeminiContents(options.History, prompt),
+		SystemInstruction: buildGeminiSystemInstruction(options.SystemPrompt, options.History),
+		SafetySettings:    p.safetySettings,
+	}
+	if len(options.Tools) > 0 {
+		declarations := make([]types.GeminiFunctionDeclaration, 0, len(options.Tools))
+		for _, tool := range options.Tools {
+			declaration := types.GeminiFunctionDeclaration{Name: tool.Name, Description: tool.Description}
+			if tool.Parameters != nil {
+				declaration.ParametersJsonSchema = tool.Parameters
+			}
+			declarations = append(declarations, declaration)
+		}
+		payload.Tools = []types.GeminiTool{{FunctionDeclarations: declarations}}
+		if options.ToolMode == ToolModeNone {
+			payload.ToolConfig = &types.GeminiToolConfig{}
+			payload.ToolConfig.FunctionCallingConfig.Mode = "NONE"
+		}
+	}
+	if options.Temperature != nil || options.TopP != nil || options.MaxTokens > 0 || options.ResponseSchema != nil {
+		payload.GenerationConfig = &types.GeminiGenerationConfig{
+			Temperature:     options.Temperature,
+			TopP:            options.TopP,
+			MaxOutputTokens: options.MaxTokens,
+		}
+		if options.ResponseSchema != nil {
+			payload.GenerationConfig.ResponseMimeType = "application/json"
+			payload.GenerationConfig.ResponseJsonSchema = options.ResponseSchema.Schema
+		}
+	}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("JSON 데이터 생성 실패: %v", err)
+	}
+
+	url := fmt.Sprintf("%s/models/%s:%s", geminiBaseURL, options.Model, method)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("HTTP 요청 객체 생성 실패: %v", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-goog-api-key", p.apiKey)
+	return req, nil
+}
+
+func usageFromGemini(metadata *types.GeminiUsageMetadata) Usage {
+	if metadata == nil {
+		return Usage{}
+	}
+	return Usage{
+		InputTokens:  metadata.PromptTokenCount,
+		OutputTokens: metadata.CandidatesTokenCount,
+		TotalTokens:  metadata.TotalTokenCount,
 	}
+}
 
-	if len(apiResponse.Candidates) > 0 && len(apiResponse.Candidates[0].Content.Parts) > 0 {
-		return apiResponse.Candidates[0].Content.Parts[0].Text, nil
+// buildGeminiSystemInstruction 은 시스템 프롬프트와 이력의 "system" 메시지를 systemInstruction 의 파트로 모읍니다.
+// Gemini 의 contents 에는 user/model 역할만 쓸 수 있습니다. 둘 다 없으면 nil 입니다.
+func buildGeminiSystemInstruction(systemPrompt string, history []Message) *types.GeminiContent {
+	var parts []types.GeminiPart
+	if systemPrompt != "" {
+		parts = append(parts, types.GeminiPart{Text: systemPrompt})
 	}
+	for _, m := range history {
+		if m.Role == "system" && m.Content != "" {
+			parts = append(parts, types.GeminiPart{Text: m.Content})
+		}
+	}
+	if len(parts) == 0 {
+		return nil
+	}
+	return &types.GeminiContent{Parts: parts}
+}
+
+// buildGeminiContents 는 대화 이력을 Gemini 역할(user/model)로 바꾸고 이번 프롬프트를 덧붙입니다.
+// 도구 결과("tool" 턴)는 functionResponse 파트가 되며, 연속된 결과는 한 턴으로 묶습니다.
+// "system" 메시지는 buildGeminiSystemInstruction 이 systemInstruction 으로 옮기므로 건너뜁니다.
+// 도구 결과 다음 호출처럼 prompt 가 비어 있으면 이력만 보냅니다.
+func buildGeminiContents(history []Message, prompt string) []types.GeminiContent {
+	var contents []types.GeminiContent
+	for _, m := range history {
+		if m.Role == "system" {
+			continue
+		}
+		if m.Role == "tool" {
+			part := types.GeminiPart{FunctionResponse: &types.GeminiFunctionResponse{
+				ID:       m.ToolCallID,
+				Name:     m.Name,
+				Response: toolResultObject(m.Content),
+			}}
+			if last := len(contents) - 1; last >= 0 && contents[last].Parts[0].FunctionResponse != nil {
+				contents[last].Parts = append(contents[last].Parts, part)
+				continue
+			}
+			contents = append(contents, types.GeminiContent{Role: "user", Parts: []types.GeminiPart{part}})
+			continue
+		}
+
+		role := m.Role
+		if role == "assistant" {
+			role = "model"
+		}
+		var parts []types.GeminiPart
+		if m.Content != "" || len(m.ToolCalls) == 0 {
+			parts = append(parts, types.GeminiPart{Text: m.Content})
+		}
+		for _, call := range m.ToolCalls {
+			parts = append(parts, types.GeminiPart{FunctionCall: &types.GeminiFunctionCall{ID: call.ID, Name: call.Name, Args: call.Arguments}})
+		}
+		contents = append(contents, types.GeminiContent{Role: role, Parts: parts})
+	}
+	if prompt == "" {
+		return contents
+	}
+	return append(contents, types.GeminiContent{Role: "user", Parts: []types.GeminiPart{{Text: prompt}}})
+}
+
+// toolResultObject 는 functionResponse 가 객체만 받으므로 객체가 아닌 도구 결과를 {"result": ...} 로 감쌉니다.
+func toolResultObject(content string) any {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(content), &object); err == nil {
+		return json.RawMessage(content)
+	}
+	return map[string]string{"result": content}
+}
 
-	return "", fmt.Errorf("응답에서 텍스트를 찾을 수 없습니다")
-}
\ No newline at end of file
+var (
+	sharedGeminiOnce     sync.Once
+	sharedGeminiProvider *GeminiProvider
+	sharedGeminiErr      error
+)
+
+// GenerateContentWithHTTP 는 GEMINI_API_KEY(또는 GOOGLE_API_KEY) 와 기본 모델로 호출하는 기존 진입점입니다.
+// 프로바이더는 처음 호출할 때 한 번만 만들어 재사용합니다. 새 코드는 Provider 를 주입받아 사용하세요.
+func GenerateContentWithHTTP(ctx context.Context, prompt string) (string, error) {
+	sharedGeminiOnce.Do(func() {
+		apiKey := os.Getenv("GEMINI_API_KEY")
+		if apiKey == "" {
+			apiKey = os.Getenv("GOOGLE_API_KEY")
+		}
+		sharedGeminiProvider, sharedGeminiErr = NewGeminiProvider(types.LLMConfig{APIKey: apiKey})
+	})
+	if sharedGeminiErr != nil {
+		return "", sharedGeminiErr
+	}
+	provider := sharedGeminiProvider
+
+	resp, err := provider.Generate(ctx, prompt)
+	if err != nil {
+		return "", err
+	}
+	return resp.Text, nil
+}
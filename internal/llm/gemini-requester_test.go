@@ -0,0 +1,32 @@
+package llm
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestGeminiSystemHistory(t *testing.T) {
+	history := []Message{
+		{Role: "system", Content: "You are the game master."},
+		{Role: "user", Content: "Where are we?"},
+		{Role: "assistant", Content: "In the tavern."},
+	}
+
+	contents := buildGeminiContents(history, "Who is here?")
+	var roles []string
+	for _, content := range contents {
+		roles = append(roles, content.Role)
+	}
+	if want := []string{"user", "model", "user"}; !slices.Equal(roles, want) {
+		t.Errorf("content roles = %v, want %v", roles, want)
+	}
+
+	instruction := buildGeminiSystemInstruction("Answer briefly.", history)
+	if instruction == nil || len(instruction.Parts) != 2 ||
+		instruction.Parts[0].Text != "Answer briefly." || instruction.Parts[1].Text != "You are the game master." {
+		t.Errorf("systemInstruction = %+v, want system prompt then system history", instruction)
+	}
+	if buildGeminiSystemInstruction("", history[1:]) != nil {
+		t.Error("systemInstruction without system text: want nil")
+	}
+}
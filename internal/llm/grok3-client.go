@@ -1,74 +1,61 @@
 package llm
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
 	"github.com/JCSong-89/trpg-rag-game/pkg/types"
-	"io"
-	"log"
-	"net/http"
 	"os"
 )
 
 // API 엔드포인트 URL
-const apiURL = "https://api.x.ai/v1/chat/completions"
+const grokBaseURL = "https://api.x.ai/v1"
 
-func Grok3Client(prompt string) (string, error) {
-	apiKey := os.Getenv("XAI_API_KEY")
-	if apiKey == "" {
-		return "", fmt.Errorf("XAI_API_KEY 환경 변수를 설정해주세요")
-	}
+const (
+	defaultGrokModel       = "grok-3-mini-beta"
+	defaultGrokTemperature = 0.7
+	defaultGrokMaxTokens   = 1024
+)
 
-	requestPayload := types.GrokHttpRequest{
-		Messages: []types.GrokMessage{
-			{Role: "user", Content: "Hello, Grok! What's the weather like in Seoul today?"},
-		},
-		Model:       "grok-3-mini-beta",
-		Temperature: 0.7,
-		MaxTokens:   1024,
-		Stream:      false,
-	}
+// GrokProvider 는 xAI 의 OpenAI 호환 chat completions 엔드포인트를 사용합니다.
+type GrokProvider struct {
+	*OpenAICompatibleProvider
+}
 
-	jsonData, err := json.Marshal(requestPayload)
-	if err != nil {
-		log.Fatalf("JSON 마샬링 실패: %v", err)
+func NewGrokProvider(cfg types.LLMConfig) (*GrokProvider, error) {
+	if cfg.APIKey == "" && requiresAPIKey(cfg.HTTP) {
+		return nil, fmt.Errorf("XAI_API_KEY 환경 변수를 설정해주세요")
 	}
 
-	req, err := http.NewRequest("POST", apiURL, bytes.NewBuffer(jsonData))
-	if err != nil {
-		log.Fatalf("HTTP 요청 생성 실패: %v", err)
+	defaults := defaultOptions(cfg, defaultGrokModel)
+	if defaults.Temperature == nil {
+		temperature := defaultGrokTemperature
+		defaults.Temperature = &temperature
 	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+apiKey)
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		log.Fatalf("API 요청 실패: %v", err)
+	if defaults.MaxTokens == 0 {
+		defaults.MaxTokens = defaultGrokMaxTokens
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		log.Fatalf("응답 바디 읽기 실패: %v", err)
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = grokBaseURL
 	}
-
-	if resp.StatusCode != http.StatusOK {
-		log.Fatalf("API 에러: %s, 응답: %s", resp.Status, string(body))
+	provider, err := newChatCompletionsProvider("grok", baseURL, cfg.APIKey, cfg.HTTP, defaults)
+	if err != nil {
+		return nil, err
 	}
+	return &GrokProvider{provider}, nil
+}
 
-	var apiResponse types.GrokHttpResponse
-	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		log.Fatalf("JSON 언마샬링 실패: %v", err)
+// Grok3Client 는 XAI_API_KEY 와 기본 설정으로 프롬프트 하나를 보내는 간편 함수입니다.
+func Grok3Client(prompt string) (string, error) {
+	provider, err := NewGrokProvider(types.LLMConfig{APIKey: os.Getenv("XAI_API_KEY")})
+	if err != nil {
+		return "", err
 	}
 
-	if len(apiResponse.Choices) > 0 {
-		return apiResponse.Choices[0].Message.Content, nil
+	resp, err := provider.Generate(context.Background(), prompt)
+	if err != nil {
+		return "", err
 	}
-
-	failMessage := "Grok으로부터 답변을 받지 못했습니다."
-	fmt.Println(failMessage)
-	return failMessage, nil
-}
\ No newline at end of file
+	return resp.Text, nil
+}
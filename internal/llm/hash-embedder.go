@@ -0,0 +1,78 @@
+package llm
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+)
+
+const defaultHashDimensions = 256
+
+// HashEmbedder 는 토큰 해싱으로 벡터를 만드는 결정적 임베더입니다.
+// 의미를 이해하지는 못하지만 같은 단어를 공유하는 텍스트끼리 가깝게 배치되므로
+// 네트워크 없이 돌리는 테스트와 로컬 실행에 사용합니다.
+type HashEmbedder struct {
+	dimension int
+}
+
+func NewHashEmbedder(dimension int) *HashEmbedder {
+	if dimension <= 0 {
+		dimension = defaultHashDimensions
+	}
+	return &HashEmbedder{dimension: dimension}
+}
+
+func (e *HashEmbedder) Model() string {
+	return "hash"
+}
+
+func (e *HashEmbedder) Dimension() int {
+	return e.dimension
+}
+
+func (e *HashEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	embeddings := make([][]float32, len(texts))
+	for i, text := range texts {
+		embeddings[i] = e.embedOne(text)
+	}
+	return embeddings, nil
+}
+
+func (e *HashEmbedder) embedOne(text string) []float32 {
+	vector := make([]float32, e.dimension)
+	tokens := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	for _, token := range tokens {
+		h := fnv.New64a()
+		h.Write([]byte(token))
+		sum := h.Sum64()
+
+		index := int(sum % uint64(e.dimension))
+		if sum&(1<<63) != 0 {
+			vector[index] -= 1
+		} else {
+			vector[index] += 1
+		}
+	}
+
+	var norm float64
+	for _, v := range vector {
+		norm += float64(v) * float64(v)
+	}
+	if norm == 0 {
+		return vector
+	}
+	scale := float32(1 / math.Sqrt(norm))
+	for i := range vector {
+		vector[i] *= scale
+	}
+	return vector
+}
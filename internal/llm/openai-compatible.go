@@ -0,0 +1,272 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/JCSong-89/trpg-rag-game/pkg/types"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// OpenAICompatibleProvider 는 OpenAI chat completions 스키마를 따르는 서버용 프로바이더입니다.
+// llama.cpp server, vLLM, Ollama 처럼 로컬에서 띄운 모델도 BaseURL 만 바꿔 사용할 수 있습니다.
+type OpenAICompatibleProvider struct {
+	name     string
+	baseURL  string
+	apiKey   string
+	client   *http.Client
+	defaults GenerateOptions
+}
+
+func NewOpenAICompatibleProvider(cfg types.LLMConfig) (*OpenAICompatibleProvider, error) {
+	if cfg.BaseURL == "" {
+		return nil, fmt.Errorf("LLM_BASE_URL 환경 변수를 설정해주세요 (예: http://localhost:11434/v1)")
+	}
+	if cfg.Model == "" {
+		return nil, fmt.Errorf("LLM_MODEL 환경 변수를 설정해주세요 (OpenAI 호환 서버에는 기본 모델이 없습니다)")
+	}
+	return newChatCompletionsProvider("openai", cfg.BaseURL, cfg.APIKey, cfg.HTTP, defaultOptions(cfg, ""))
+}
+
+func newChatCompletionsProvider(name string, baseURL string, apiKey string, httpCfg types.HTTPConfig, defaults GenerateOptions) (*OpenAICompatibleProvider, error) {
+	client, err := NewHTTPClient(name, httpCfg)
+	if err != nil {
+		return nil, err
+	}
+	return &OpenAICompatibleProvider{
+		name:     name,
+		baseURL:  strings.TrimRight(baseURL, "/"),
+		apiKey:   apiKey,
+		client:   client,
+		defaults: defaults,
+	}, nil
+}
+
+func (p *OpenAICompatibleProvider) Name() string {
+	return p.name
+}
+
+func (p *OpenAICompatibleProvider) ResolveOptions(opts []Option) GenerateOptions {
+	return resolveOptions(p.defaults, opts)
+}
+
+func (p *OpenAICompatibleProvider) Generate(ctx context.Context, prompt string, opts ...Option) (*Response, error) {
+	options := resolveOptions(p.defaults, opts)
+
+	req, err := p.newRequest(ctx, prompt, options, false)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s API 요청 실패: %w", p.name, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("응답 바디 읽기 실패: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s API 에러: %s, 응답: %s", p.name, resp.Status, string(body))
+	}
+
+	var apiResponse types.GrokHttpResponse
+	if err := json.Unmarshal(body, &apiResponse); err != nil {
+		return nil, fmt.Errorf("JSON 언마샬링 실패: %w", err)
+	}
+
+	if len(apiResponse.Choices) == 0 {
+		return nil, fmt.Errorf("%s 으로부터 답변을 받지 못했습니다", p.name)
+	}
+
+	model := apiResponse.Model
+	if model == "" {
+		model = options.Model
+	}
+	return &Response{
+		Text:      apiResponse.Choices[0].Message.Content,
+		Model:     model,
+		Usage:     usageFromGrok(apiResponse.Usage),
+		ToolCalls: toolCallsFromGrok(apiResponse.Choices[0].Message.ToolCalls),
+	}, nil
+}
+
+// Stream 은 stream: true 로 요청하고 SSE 로 오는 delta 를 조각 단위로 보냅니다.
+func (p *OpenAICompatibleProvider) Stream(ctx context.Context, prompt string, opts ...Option) (<-chan StreamChunk, error) {
+	options := resolveOptions(p.defaults, opts)
+
+	req, err := p.newRequest(ctx, prompt, options, true)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s API 요청 실패: %w", p.name, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%s API 에러: %s, 응답: %s", p.name, resp.Status, string(body))
+	}
+
+	chunks := make(chan StreamChunk)
+	go func() {
+		defer close(chunks)
+		defer resp.Body.Close()
+
+		var usage *types.GrokUsage
+		model := options.Model
+		err := readServerSentEvents(resp.Body, func(data []byte) (bool, error) {
+			if string(data) == "[DONE]" {
+				return false, nil
+			}
+			var event types.GrokStreamResponse
+			if err := json.Unmarshal(data, &event); err != nil {
+				return false, fmt.Errorf("스트림 이벤트 파싱 실패: %w", err)
+			}
+			if event.Usage != nil {
+				usage = event.Usage
+			}
+			if event.Model != "" {
+				model = event.Model
+			}
+			for _, choice := range event.Choices {
+				if choice.Delta.Content == "" {
+					continue
+				}
+				if !sendChunk(ctx, chunks, StreamChunk{Text: choice.Delta.Content}) {
+					return false, nil
+				}
+			}
+			return true, nil
+		})
+		if err != nil {
+			if ctx.Err() == nil {
+				sendChunk(ctx, chunks, StreamChunk{Err: err})
+			}
+			return
+		}
+		if usage != nil {
+			final := usageFromGrok(*usage)
+			sendChunk(ctx, chunks, StreamChunk{Usage: &final, Model: model})
+		}
+	}()
+	return chunks, nil
+}
+
+func (p *OpenAICompatibleProvider) newRequest(ctx context.Context, prompt string, options GenerateOptions, stream bool) (*http.Request, error) {
+	requestPayload := types.GrokHttpRequest{
+		Messages:    buildChatMessages(options, prompt),
+		Model:       options.Model,
+		Temperature: options.Temperature,
+		TopP:        options.TopP,
+		MaxTokens:   options.MaxTokens,
+		Stream:      stream,
+	}
+	if stream {
+		requestPayload.StreamOptions = &types.GrokStreamOptions{IncludeUsage: true}
+	}
+	// json_schema 응답 형식은 최상위가 객체인 스키마만 받으므로, 배열 등은 응답 검증과 재요청에 맡깁니다.
+	if options.ResponseSchema != nil && options.ResponseSchema.Schema.Type == "object" {
+		requestPayload.ResponseFormat = &types.GrokResponseFormat{
+			Type: "json_schema",
+			JSONSchema: &types.GrokJSONSchema{
+				Name:   options.ResponseSchema.Name,
+				Schema: options.ResponseSchema.Schema,
+				Strict: false,
+			},
+		}
+	}
+
+	for _, tool := range options.Tools {
+		function := types.GrokFunction{Name: tool.Name, Description: tool.Description}
+		if tool.Parameters != nil {
+			function.Parameters = tool.Parameters
+		}
+		requestPayload.Tools = append(requestPayload.Tools, types.GrokTool{Type: "function", Function: function})
+	}
+	if len(options.Tools) > 0 && options.ToolMode == ToolModeNone {
+		requestPayload.ToolChoice = "none"
+	}
+
+	jsonData, err := json.Marshal(requestPayload)
+	if err != nil {
+		return nil, fmt.Errorf("JSON 마샬링 실패: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+"/chat/completions", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("HTTP 요청 생성 실패: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+	return req, nil
+}
+
+// buildChatMessages 는 시스템 프롬프트, 대화 이력, 이번 프롬프트 순서로 메시지를 구성합니다.
+// 도구 결과 다음 호출처럼 prompt 가 비어 있으면 이력만 보냅니다.
+func buildChatMessages(options GenerateOptions, prompt string) []types.GrokMessage {
+	var messages []types.GrokMessage
+	if options.SystemPrompt != "" {
+		messages = append(messages, types.GrokMessage{Role: "system", Content: options.SystemPrompt})
+	}
+	for _, m := range options.History {
+		message := types.GrokMessage{Role: m.Role, Content: m.Content, ToolCallID: m.ToolCallID}
+		for _, call := range m.ToolCalls {
+			message.ToolCalls = append(message.ToolCalls, types.GrokToolCall{
+				ID:       call.ID,
+				Type:     "function",
+				Function: types.GrokFunctionCall{Name: call.Name, Arguments: string(call.Arguments)},
+			})
+		}
+		messages = append(messages, message)
+	}
+	if prompt == "" {
+		return messages
+	}
+	return append(messages, types.GrokMessage{Role: "user", Content: prompt})
+}
+
+// toolCallsFromGrok 은 문자열로 온 인자를 JSON 으로 옮깁니다. 모델이 잘못된 JSON 을 만들었으면
+// 문자열 그대로 JSON 문자열로 감싸 두고, 인자 검증에서 모델에게 오류를 돌려주게 합니다.
+func toolCallsFromGrok(calls []types.GrokToolCall) []ToolCall {
+	var toolCalls []ToolCall
+	for _, call := range calls {
+		arguments := json.RawMessage(call.Function.Arguments)
+		if strings.TrimSpace(call.Function.Arguments) == "" {
+			arguments = json.RawMessage("{}")
+		} else if !json.Valid(arguments) {
+			arguments, _ = json.Marshal(call.Function.Arguments)
+		}
+		toolCalls = append(toolCalls, ToolCall{ID: call.ID, Name: call.Function.Name, Arguments: arguments})
+	}
+	return toolCalls
+}
+
+func usageFromGrok(u types.GrokUsage) Usage {
+	usage := Usage{
+		InputTokens:  u.PromptTokens,
+		OutputTokens: u.CompletionTokens,
+		TotalTokens:  u.TotalTokens,
+	}
+	if usage.InputTokens == 0 {
+		usage.InputTokens = u.InputTokens
+	}
+	if usage.OutputTokens == 0 {
+		usage.OutputTokens = u.OutputTokens
+	}
+	if usage.TotalTokens == 0 {
+		usage.TotalTokens = usage.InputTokens + usage.OutputTokens
+	}
+	return usage
+}
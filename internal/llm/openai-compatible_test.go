@@ -0,0 +1,42 @@
+package llm
+
+import (
+	"testing"
+
+	"github.com/JCSong-89/trpg-rag-game/pkg/types"
+)
+
+func TestOpenAICompatibleRequiresModel(t *testing.T) {
+	cfg := types.LLMConfig{Provider: "openai", BaseURL: "http://localhost:11434/v1"}
+	if _, err := NewOpenAICompatibleProvider(cfg); err == nil {
+		t.Error("NewOpenAICompatibleProvider() without model: want error")
+	}
+
+	cfg.Model = "llama3"
+	provider, err := NewOpenAICompatibleProvider(cfg)
+	if err != nil {
+		t.Fatalf("NewOpenAICompatibleProvider() error = %v", err)
+	}
+	if model := provider.ResolveOptions(nil).Model; model != "llama3" {
+		t.Errorf("default model = %q, want llama3", model)
+	}
+}
+
+func TestRoutedOpenAIUsesRouteModel(t *testing.T) {
+	cfg := types.LLMConfig{
+		Provider: "gemini",
+		APIKey:   "key",
+		Routes: map[string][]types.LLMRoute{
+			RouteDefault: {{Provider: "openai", Model: "llama3"}},
+		},
+		Backends: map[string]types.LLMBackend{"openai": {BaseURL: "http://localhost:11434/v1"}},
+	}
+	if _, err := NewRoutedProvider(cfg); err != nil {
+		t.Fatalf("NewRoutedProvider() error = %v", err)
+	}
+
+	cfg.Routes[RouteDefault] = []types.LLMRoute{{Provider: "openai"}}
+	if _, err := NewRoutedProvider(cfg); err == nil {
+		t.Error("NewRoutedProvider() with no model for openai: want error")
+	}
+}
@@ -0,0 +1,107 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/JCSong-89/trpg-rag-game/pkg/types"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// OpenAIEmbedder 는 OpenAI 호환 /embeddings 엔드포인트를 사용합니다.
+// 응답 벡터 크기를 미리 알 수 없으므로 EMBEDDING_DIMENSION 설정이 필요합니다.
+type OpenAIEmbedder struct {
+	baseURL   string
+	apiKey    string
+	model     string
+	dimension int
+	client    *http.Client
+}
+
+func NewOpenAIEmbedder(cfg types.EmbeddingConfig) (*OpenAIEmbedder, error) {
+	if cfg.BaseURL == "" {
+		return nil, fmt.Errorf("EMBEDDING_BASE_URL 환경 변수를 설정해주세요 (예: http://localhost:11434/v1)")
+	}
+	if cfg.Model == "" {
+		return nil, fmt.Errorf("EMBEDDING_MODEL 환경 변수를 설정해주세요")
+	}
+	if cfg.Dimension <= 0 {
+		return nil, fmt.Errorf("EMBEDDING_DIMENSION 환경 변수를 설정해주세요")
+	}
+	client, err := NewHTTPClient("embeddings", cfg.HTTP)
+	if err != nil {
+		return nil, err
+	}
+	return &OpenAIEmbedder{
+		baseURL:   strings.TrimRight(cfg.BaseURL, "/"),
+		apiKey:    cfg.APIKey,
+		model:     cfg.Model,
+		dimension: cfg.Dimension,
+		client:    client,
+	}, nil
+}
+
+func (e *OpenAIEmbedder) Model() string {
+	return e.model
+}
+
+func (e *OpenAIEmbedder) Dimension() int {
+	return e.dimension
+}
+
+func (e *OpenAIEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	jsonData, err := json.Marshal(types.OpenAIEmbeddingRequest{Model: e.model, Input: texts})
+	if err != nil {
+		return nil, fmt.Errorf("JSON 인코딩 실패: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", e.baseURL+"/embeddings", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("HTTP 요청 생성 실패: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if e.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+e.apiKey)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("임베딩 API 호출 실패: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("응답 바디 읽기 실패: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("임베딩 API가 에러를 반환했습니다. 상태 코드: %d, 응답: %s", resp.StatusCode, string(body))
+	}
+
+	var apiResponse types.OpenAIEmbeddingResponse
+	if err := json.Unmarshal(body, &apiResponse); err != nil {
+		return nil, fmt.Errorf("JSON 응답 디코딩 실패: %w", err)
+	}
+
+	embeddings := make([][]float32, len(texts))
+	for _, d := range apiResponse.Data {
+		if d.Index < 0 || d.Index >= len(texts) {
+			return nil, fmt.Errorf("임베딩 응답의 인덱스가 범위를 벗어났습니다: %d", d.Index)
+		}
+		if len(d.Embedding) != e.dimension {
+			return nil, fmt.Errorf("임베딩 차원이 설정과 다릅니다 (설정: %d, 응답: %d)", e.dimension, len(d.Embedding))
+		}
+		embeddings[d.Index] = d.Embedding
+	}
+	for i, embedding := range embeddings {
+		if embedding == nil {
+			return nil, fmt.Errorf("%d번째 입력의 임베딩이 응답에 없습니다", i)
+		}
+	}
+	return embeddings, nil
+}
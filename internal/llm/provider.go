@@ -0,0 +1,152 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"github.com/JCSong-89/trpg-rag-game/pkg/types"
+)
+
+// Provider 는 텍스트 생성 LLM 백엔드의 공통 인터페이스입니다.
+type Provider interface {
+	Name() string
+	Generate(ctx context.Context, prompt string, opts ...Option) (*Response, error)
+}
+
+// 파이프라인 단계 이름입니다.
+const (
+	StageExtraction  = "extraction"
+	StageQueryNER    = "query_ner"
+	StageEvaluation  = "evaluation"
+	StageFinalAnswer = "final_answer"
+	StageSummary     = "summary"
+	StageFollowUp    = "follow_up"
+)
+
+// Cached 가 true 이면 응답 캐시에서 꺼낸 결과이며 이번 호출로 소비한 토큰은 없습니다.
+// ToolCalls 가 있으면 모델이 텍스트 대신 도구 실행을 요청한 것입니다.
+type Response struct {
+	Text      string
+	Model     string
+	Usage     Usage
+	Cached    bool
+	ToolCalls []ToolCall
+}
+
+type Usage struct {
+	InputTokens  int
+	OutputTokens int
+	TotalTokens  int
+}
+
+// Message 는 대화 이력의 한 턴입니다. Role 은 "user", "assistant", "tool" 을 사용합니다.
+// 도구 호출을 요청한 어시스턴트 턴은 ToolCalls 를, 그 결과를 담은 "tool" 턴은 ToolCallID 와 Name 을 채웁니다.
+type Message struct {
+	Role       string
+	Content    string
+	ToolCalls  []ToolCall
+	ToolCallID string
+	Name       string
+}
+
+type GenerateOptions struct {
+	Model          string
+	Temperature    *float64
+	TopP           *float64
+	MaxTokens      int
+	SystemPrompt   string
+	History        []Message
+	ResponseSchema *StructuredOutput
+	Stage          string
+	NoCache        bool
+	Tools          []Tool
+	ToolMode       string
+}
+
+type Option func(*GenerateOptions)
+
+func WithModel(model string) Option {
+	return func(o *GenerateOptions) { o.Model = model }
+}
+
+func WithTemperature(temperature float64) Option {
+	return func(o *GenerateOptions) { o.Temperature = &temperature }
+}
+
+func WithTopP(topP float64) Option {
+	return func(o *GenerateOptions) { o.TopP = &topP }
+}
+
+func WithMaxTokens(maxTokens int) Option {
+	return func(o *GenerateOptions) { o.MaxTokens = maxTokens }
+}
+
+func WithSystemPrompt(systemPrompt string) Option {
+	return func(o *GenerateOptions) { o.SystemPrompt = systemPrompt }
+}
+
+// WithStage 는 호출이 속한 파이프라인 단계를 표시합니다. 사용량 집계에 단계별 구분으로 쓰입니다.
+func WithStage(stage string) Option {
+	return func(o *GenerateOptions) { o.Stage = stage }
+}
+
+// WithHistory 는 이번 프롬프트 앞에 보낼 이전 대화 턴을 지정합니다.
+func WithHistory(history []Message) Option {
+	return func(o *GenerateOptions) { o.History = history }
+}
+
+// resolveOptions 는 설정값을 기본으로 하고 호출 시 전달된 옵션으로 덮어씁니다.
+func resolveOptions(defaults GenerateOptions, opts []Option) GenerateOptions {
+	resolved := defaults
+	for _, opt := range opts {
+		opt(&resolved)
+	}
+	return resolved
+}
+
+// optionResolver 는 설정의 기본값에 호출 옵션을 덮어써 실제로 보낼 옵션을 알려주는 프로바이더입니다.
+// 응답 캐시가 기본 모델이나 생성 파라미터가 다른 호출을 같은 키로 보지 않도록 씁니다.
+type optionResolver interface {
+	ResolveOptions(opts []Option) GenerateOptions
+}
+
+// ResolvedOptions 는 provider 가 opts 로 호출될 때 실제로 쓰는 옵션입니다. 기본값을 알 수 없는 프로바이더는 opts 만 반영합니다.
+func ResolvedOptions(provider Provider, opts []Option) GenerateOptions {
+	if resolver, ok := provider.(optionResolver); ok {
+		return resolver.ResolveOptions(opts)
+	}
+	return resolveOptions(GenerateOptions{}, opts)
+}
+
+func defaultOptions(cfg types.LLMConfig, defaultModel string) GenerateOptions {
+	model := cfg.Model
+	if model == "" {
+		model = defaultModel
+	}
+	return GenerateOptions{
+		Model:        model,
+		Temperature:  cfg.Temperature,
+		TopP:         cfg.TopP,
+		MaxTokens:    cfg.MaxTokens,
+		SystemPrompt: cfg.SystemPrompt,
+	}
+}
+
+// NewProvider 는 cfg.Routes 가 있으면 단계별 라우팅 프로바이더를, 없으면 cfg.Provider 하나를 만듭니다.
+func NewProvider(cfg types.LLMConfig) (Provider, error) {
+	if len(cfg.Routes) > 0 {
+		return NewRoutedProvider(cfg)
+	}
+
+	switch cfg.Provider {
+	case "", "gemini":
+		return NewGeminiProvider(cfg)
+	case "grok":
+		return NewGrokProvider(cfg)
+	case "openai", "local":
+		return NewOpenAICompatibleProvider(cfg)
+	case "scripted":
+		return NewScriptedProviderFromFile(cfg.ScriptPath)
+	default:
+		return nil, fmt.Errorf("지원하지 않는 LLM 프로바이더입니다: %s", cfg.Provider)
+	}
+}
@@ -0,0 +1,233 @@
+package llm
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/JCSong-89/trpg-rag-game/pkg/types"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	defaultResponseCacheSize = 256
+	defaultResponseCacheTTL  = 24 * time.Hour
+)
+
+// WithNoCache 는 응답 캐시를 건너뛰게 합니다. 내레이션처럼 매번 새로운 결과가 필요한 호출에 사용합니다.
+func WithNoCache() Option {
+	return func(o *GenerateOptions) { o.NoCache = true }
+}
+
+type cachedResponse struct {
+	Text      string     `json:"text"`
+	Model     string     `json:"model"`
+	ToolCalls []ToolCall `json:"toolCalls,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// CachedProvider 는 모델, 생성 파라미터, 프롬프트의 해시를 키로 응답을 재사용합니다.
+// 메모리 LRU 를 먼저 보고, Dir 가 설정되어 있으면 디스크에 저장된 응답도 찾아봅니다.
+// 스트리밍 호출은 캐시하지 않고, 구조화 출력 호출은 응답이 스키마에 맞을 때만 캐시합니다.
+type CachedProvider struct {
+	inner Provider
+	scope string
+	ttl   time.Duration
+	size  int
+	dir   string
+
+	mu    sync.Mutex
+	order *list.List
+	items map[string]*list.Element
+}
+
+type lruEntry struct {
+	key   string
+	value cachedResponse
+}
+
+// NewCachedProvider 의 scope 는 같은 옵션이라도 설정된 기본 모델이 다르면 다른 키가 되도록 키에 섞는 값입니다.
+func NewCachedProvider(inner Provider, scope string, cfg types.ResponseCacheConfig) (*CachedProvider, error) {
+	size := cfg.Size
+	if size <= 0 {
+		size = defaultResponseCacheSize
+	}
+	ttl := cfg.TTL
+	if ttl <= 0 {
+		ttl = defaultResponseCacheTTL
+	}
+	if cfg.Dir != "" {
+		if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+			return nil, fmt.Errorf("응답 캐시 디렉터리 생성 실패 (%s): %w", cfg.Dir, err)
+		}
+	}
+
+	return &CachedProvider{
+		inner: inner,
+		scope: scope,
+		ttl:   ttl,
+		size:  size,
+		dir:   cfg.Dir,
+		order: list.New(),
+		items: map[string]*list.Element{},
+	}, nil
+}
+
+func (p *CachedProvider) Name() string {
+	return p.inner.Name()
+}
+
+func (p *CachedProvider) Generate(ctx context.Context, prompt string, opts ...Option) (*Response, error) {
+	options := ResolvedOptions(p.inner, opts)
+	if options.NoCache {
+		return p.inner.Generate(ctx, prompt, opts...)
+	}
+
+	key, err := p.key(prompt, options)
+	if err != nil {
+		return nil, err
+	}
+	if cached, ok := p.lookup(key); ok && validStructuredText(cached.Text, options.ResponseSchema) {
+		return &Response{Text: cached.Text, Model: cached.Model, ToolCalls: cached.ToolCalls, Cached: true}, nil
+	}
+
+	resp, err := p.inner.Generate(ctx, prompt, opts...)
+	if err != nil {
+		return nil, err
+	}
+	// 스키마에 맞지 않는 응답을 저장하면 GenerateJSON 의 재요청이 같은 응답을 다시 읽게 됩니다.
+	if validStructuredText(resp.Text, options.ResponseSchema) {
+		p.store(key, cachedResponse{Text: resp.Text, Model: resp.Model, ToolCalls: resp.ToolCalls, CreatedAt: time.Now()})
+	}
+	return resp, nil
+}
+
+// validStructuredText 는 구조화 출력을 요청하지 않았거나, 응답이 요청한 스키마에 맞으면 true 입니다.
+func validStructuredText(text string, responseSchema *StructuredOutput) bool {
+	if responseSchema == nil || responseSchema.Schema == nil {
+		return true
+	}
+	var generic any
+	if err := json.Unmarshal([]byte(stripCodeFence(text)), &generic); err != nil {
+		return false
+	}
+	return responseSchema.Schema.Validate(generic) == nil
+}
+
+func (p *CachedProvider) Stream(ctx context.Context, prompt string, opts ...Option) (<-chan StreamChunk, error) {
+	return GenerateStream(ctx, p.inner, prompt, opts...)
+}
+
+// key 는 단계(Stage)와 캐시 우회 여부를 뺀, 응답에 영향을 주는 옵션만으로 만듭니다. options 에는 프로바이더의 기본값이 반영되어 있습니다.
+func (p *CachedProvider) key(prompt string, options GenerateOptions) (string, error) {
+	material, err := json.Marshal(struct {
+		Provider       string
+		Scope          string
+		Model          string
+		Temperature    *float64
+		TopP           *float64
+		MaxTokens      int
+		SystemPrompt   string
+		History        []Message
+		ResponseSchema *StructuredOutput
+		Tools          []Tool
+		ToolMode       string
+		Prompt         string
+	}{
+		Provider:       p.inner.Name(),
+		Scope:          p.scope,
+		Model:          options.Model,
+		Temperature:    options.Temperature,
+		TopP:           options.TopP,
+		MaxTokens:      options.MaxTokens,
+		SystemPrompt:   options.SystemPrompt,
+		History:        options.History,
+		ResponseSchema: options.ResponseSchema,
+		Tools:          options.Tools,
+		ToolMode:       options.ToolMode,
+		Prompt:         prompt,
+	})
+	if err != nil {
+		return "", fmt.Errorf("응답 캐시 키 생성 실패: %w", err)
+	}
+	sum := sha256.Sum256(material)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func (p *CachedProvider) lookup(key string) (cachedResponse, bool) {
+	p.mu.Lock()
+	if element, ok := p.items[key]; ok {
+		entry := element.Value.(*lruEntry)
+		if time.Since(entry.value.CreatedAt) < p.ttl {
+			p.order.MoveToFront(element)
+			p.mu.Unlock()
+			return entry.value, true
+		}
+		p.order.Remove(element)
+		delete(p.items, key)
+	}
+	p.mu.Unlock()
+
+	if p.dir == "" {
+		return cachedResponse{}, false
+	}
+
+	path := filepath.Join(p.dir, key+".json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.Printf("경고: 응답 캐시 읽기 실패 (%s): %v", path, err)
+		}
+		return cachedResponse{}, false
+	}
+
+	var value cachedResponse
+	if err := json.Unmarshal(data, &value); err != nil || time.Since(value.CreatedAt) >= p.ttl {
+		os.Remove(path)
+		return cachedResponse{}, false
+	}
+	p.remember(key, value)
+	return value, true
+}
+
+func (p *CachedProvider) store(key string, value cachedResponse) {
+	p.remember(key, value)
+	if p.dir == "" {
+		return
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		log.Printf("경고: 응답 캐시 인코딩 실패: %v", err)
+		return
+	}
+	path := filepath.Join(p.dir, key+".json")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		log.Printf("경고: 응답 캐시 저장 실패 (%s): %v", path, err)
+	}
+}
+
+func (p *CachedProvider) remember(key string, value cachedResponse) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if element, ok := p.items[key]; ok {
+		element.Value.(*lruEntry).value = value
+		p.order.MoveToFront(element)
+		return
+	}
+
+	p.items[key] = p.order.PushFront(&lruEntry{key: key, value: value})
+	for p.order.Len() > p.size {
+		oldest := p.order.Back()
+		p.order.Remove(oldest)
+		delete(p.items, oldest.Value.(*lruEntry).key)
+	}
+}
@@ -0,0 +1,185 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/JCSong-89/trpg-rag-game/pkg/types"
+	"log"
+)
+
+// RouteDefault 는 LLM_ROUTES 에서 따로 지정하지 않은 단계가 쓰는 후보 목록의 이름입니다.
+const RouteDefault = "default"
+
+type routeTarget struct {
+	provider Provider
+	model    string
+}
+
+func (t routeTarget) String() string {
+	if t.model == "" {
+		return t.provider.Name()
+	}
+	return t.provider.Name() + "/" + t.model
+}
+
+// options 는 호출자의 옵션 뒤에 후보의 모델을 붙입니다. 라우팅에 지정한 모델이 호출자의 WithModel 보다 우선합니다.
+func (t routeTarget) options(opts []Option) []Option {
+	if t.model == "" {
+		return opts
+	}
+	return append(append([]Option(nil), opts...), WithModel(t.model))
+}
+
+// RoutedProvider 는 호출의 단계(WithStage)에 따라 후보 모델 목록을 고르고, 앞의 후보가 실패하면
+// 다음 후보로 넘어갑니다. 같은 이름의 프로바이더는 하나만 만들어 HTTP 클라이언트와 서킷 브레이커를 공유합니다.
+type RoutedProvider struct {
+	routes   map[string][]routeTarget
+	fallback []routeTarget
+}
+
+func NewRoutedProvider(cfg types.LLMConfig) (*RoutedProvider, error) {
+	providers := map[string]Provider{}
+	// providerFor 는 프로바이더를 처음 만들 때 model 을 기본 모델로 씁니다. 기본 모델이 없는 OpenAI 호환 서버도
+	// 라우팅에 모델을 적으면 LLM_MODEL 없이 쓸 수 있습니다.
+	providerFor := func(name string, model string) (Provider, error) {
+		if provider, ok := providers[name]; ok {
+			return provider, nil
+		}
+
+		backendCfg := cfg
+		backendCfg.Routes = nil
+		backendCfg.Provider = name
+		if name != cfg.Provider {
+			backendCfg.Model = ""
+			backendCfg.BaseURL = ""
+			backendCfg.APIKey = ""
+		}
+		if backendCfg.Model == "" {
+			backendCfg.Model = model
+		}
+		if backend, ok := cfg.Backends[name]; ok {
+			backendCfg.BaseURL = backend.BaseURL
+			backendCfg.APIKey = backend.APIKey
+		}
+
+		provider, err := NewProvider(backendCfg)
+		if err != nil {
+			return nil, fmt.Errorf("라우팅 프로바이더 %s 생성 실패: %w", name, err)
+		}
+		providers[name] = provider
+		return provider, nil
+	}
+
+	router := &RoutedProvider{routes: map[string][]routeTarget{}}
+	for stage, routes := range cfg.Routes {
+		for _, route := range routes {
+			provider, err := providerFor(route.Provider, route.Model)
+			if err != nil {
+				return nil, err
+			}
+			router.routes[stage] = append(router.routes[stage], routeTarget{provider: provider, model: route.Model})
+		}
+	}
+
+	if _, ok := router.routes[RouteDefault]; !ok {
+		provider, err := providerFor(cfg.Provider, cfg.Model)
+		if err != nil {
+			return nil, err
+		}
+		router.fallback = []routeTarget{{provider: provider, model: cfg.Model}}
+	}
+	return router, nil
+}
+
+func (p *RoutedProvider) Name() string {
+	return "router"
+}
+
+func (p *RoutedProvider) targetsFor(stage string) []routeTarget {
+	if targets, ok := p.routes[stage]; ok {
+		return targets
+	}
+	if targets, ok := p.routes[RouteDefault]; ok {
+		return targets
+	}
+	return p.fallback
+}
+
+// ResolveOptions 는 단계의 첫 번째 후보가 쓸 옵션을 돌려줍니다.
+func (p *RoutedProvider) ResolveOptions(opts []Option) GenerateOptions {
+	targets := p.targetsFor(resolveOptions(GenerateOptions{}, opts).Stage)
+	if len(targets) == 0 {
+		return resolveOptions(GenerateOptions{}, opts)
+	}
+	return ResolvedOptions(targets[0].provider, targets[0].options(opts))
+}
+
+func (p *RoutedProvider) Generate(ctx context.Context, prompt string, opts ...Option) (*Response, error) {
+	stage := resolveOptions(GenerateOptions{}, opts).Stage
+
+	var errs []error
+	for _, target := range p.targetsFor(stage) {
+		resp, err := target.provider.Generate(ctx, prompt, target.options(opts)...)
+		if err == nil {
+			return resp, nil
+		}
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		log.Printf("경고: %s 단계의 %s 호출 실패, 다음 후보로 넘어갑니다: %v", stageLabel(stage), target, err)
+		errs = append(errs, fmt.Errorf("%s: %w", target, err))
+	}
+	return nil, fmt.Errorf("%s 단계의 모든 후보 모델 호출이 실패했습니다: %w", stageLabel(stage), errors.Join(errs...))
+}
+
+// Stream 은 첫 청크를 받기 전에 실패한 후보만 다음 후보로 넘깁니다.
+// 이미 일부 텍스트를 내보낸 뒤의 실패는 답변이 섞이지 않도록 그대로 전달합니다.
+func (p *RoutedProvider) Stream(ctx context.Context, prompt string, opts ...Option) (<-chan StreamChunk, error) {
+	stage := resolveOptions(GenerateOptions{}, opts).Stage
+
+	var errs []error
+	for _, target := range p.targetsFor(stage) {
+		upstream, err := GenerateStream(ctx, target.provider, prompt, target.options(opts)...)
+		if err == nil {
+			first, ok := <-upstream
+			if !ok || first.Err == nil {
+				return forwardStream(ctx, first, ok, upstream), nil
+			}
+			err = first.Err
+			go func() {
+				for range upstream {
+				}
+			}()
+		}
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		log.Printf("경고: %s 단계의 %s 스트리밍 실패, 다음 후보로 넘어갑니다: %v", stageLabel(stage), target, err)
+		errs = append(errs, fmt.Errorf("%s: %w", target, err))
+	}
+	return nil, fmt.Errorf("%s 단계의 모든 후보 모델 스트리밍이 실패했습니다: %w", stageLabel(stage), errors.Join(errs...))
+}
+
+func forwardStream(ctx context.Context, first StreamChunk, hasFirst bool, upstream <-chan StreamChunk) <-chan StreamChunk {
+	chunks := make(chan StreamChunk)
+	go func() {
+		defer close(chunks)
+		if hasFirst && !sendChunk(ctx, chunks, first) {
+			return
+		}
+		for chunk := range upstream {
+			if !sendChunk(ctx, chunks, chunk) {
+				return
+			}
+		}
+	}()
+	return chunks
+}
+
+func stageLabel(stage string) string {
+	if stage == "" {
+		return RouteDefault
+	}
+	return stage
+}
@@ -0,0 +1,183 @@
+package llm
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// JSONSchema 는 구조화 출력 요청과 응답 검증에 쓰는 JSON Schema 의 부분 집합입니다.
+// Type 이 비어 있으면 어떤 값이든 허용합니다.
+type JSONSchema struct {
+	Type                 string                 `json:"type,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	AdditionalProperties *JSONSchema            `json:"additionalProperties,omitempty"`
+	Enum                 []string               `json:"enum,omitempty"`
+}
+
+// SchemaFor 는 Go 값의 타입에서 JSON Schema 를 만듭니다.
+// 필드 이름은 json 태그를 따르고, omitempty 가 없는 필드는 포인터/맵/인터페이스를 제외하고 required 로 표시합니다.
+// `jsonschema:"-"` 태그가 붙은 필드는 모델이 채우지 않는 값으로 보고 스키마에서 뺍니다.
+func SchemaFor(v any) *JSONSchema {
+	return schemaForType(reflect.TypeOf(v))
+}
+
+func schemaForType(t reflect.Type) *JSONSchema {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil {
+		return &JSONSchema{}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &JSONSchema{Type: "string"}
+	case reflect.Bool:
+		return &JSONSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &JSONSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &JSONSchema{Type: "array", Items: schemaForType(t.Elem())}
+	case reflect.Map:
+		return &JSONSchema{Type: "object", AdditionalProperties: schemaForType(t.Elem())}
+	case reflect.Struct:
+		return schemaForStruct(t)
+	default:
+		return &JSONSchema{}
+	}
+}
+
+func schemaForStruct(t reflect.Type) *JSONSchema {
+	schema := &JSONSchema{Type: "object", Properties: map[string]*JSONSchema{}}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() || field.Tag.Get("jsonschema") == "-" {
+			continue
+		}
+
+		name := field.Name
+		omitEmpty := false
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			parts := strings.Split(tag, ",")
+			if parts[0] == "-" {
+				continue
+			}
+			if parts[0] != "" {
+				name = parts[0]
+			}
+			for _, option := range parts[1:] {
+				if option == "omitempty" {
+					omitEmpty = true
+				}
+			}
+		}
+
+		schema.Properties[name] = schemaForType(field.Type)
+		if !omitEmpty && !isOptionalKind(field.Type.Kind()) {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	return schema
+}
+
+func isOptionalKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Pointer, reflect.Map, reflect.Interface:
+		return true
+	default:
+		return false
+	}
+}
+
+// Validate 는 json.Unmarshal 로 any 에 디코딩한 값이 스키마를 따르는지 검사합니다.
+func (s *JSONSchema) Validate(value any) error {
+	return s.validate("$", value)
+}
+
+func (s *JSONSchema) validate(path string, value any) error {
+	if s == nil || s.Type == "" {
+		return nil
+	}
+
+	switch s.Type {
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%s: 문자열이어야 합니다", path)
+		}
+		if len(s.Enum) > 0 && !containsString(s.Enum, str) {
+			return fmt.Errorf("%s: 허용되지 않은 값입니다 (%q, 허용: %v)", path, str, s.Enum)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s: 불리언이어야 합니다", path)
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return fmt.Errorf("%s: 숫자여야 합니다", path)
+		}
+	case "integer":
+		number, ok := value.(float64)
+		if !ok || number != math.Trunc(number) {
+			return fmt.Errorf("%s: 정수여야 합니다", path)
+		}
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			return fmt.Errorf("%s: 배열이어야 합니다", path)
+		}
+		for i, item := range items {
+			if err := s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item); err != nil {
+				return err
+			}
+		}
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: 객체여야 합니다", path)
+		}
+		for _, name := range s.Required {
+			if _, exists := object[name]; !exists {
+				return fmt.Errorf("%s: 필수 필드 %q 가 없습니다", path, name)
+			}
+		}
+
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fieldValue := object[key]
+			fieldSchema, known := s.Properties[key]
+			if !known {
+				fieldSchema = s.AdditionalProperties
+			}
+			if fieldValue == nil && !containsString(s.Required, key) {
+				continue
+			}
+			if err := fieldSchema.validate(path+"."+key, fieldValue); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func containsString(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// ScriptRule 은 프롬프트에 Match 문자열이 포함되면 Response 를 돌려주는 규칙입니다.
+// Match 가 비어 있으면 모든 프롬프트에 대응합니다.
+type ScriptRule struct {
+	Match    string `json:"match"`
+	Response string `json:"response"`
+}
+
+// ScriptedProvider 는 네트워크 없이 미리 정해 둔 응답을 돌려주는 결정적 프로바이더입니다.
+// 테스트나 오프라인 실행에서 실제 LLM 대신 사용합니다.
+type ScriptedProvider struct {
+	rules []ScriptRule
+
+	mu      sync.Mutex
+	prompts []string
+}
+
+func NewScriptedProvider(rules ...ScriptRule) *ScriptedProvider {
+	return &ScriptedProvider{rules: rules}
+}
+
+func NewScriptedProviderFromFile(path string) (*ScriptedProvider, error) {
+	if path == "" {
+		return nil, fmt.Errorf("LLM_SCRIPT_PATH 환경 변수를 설정해주세요")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("스크립트 파일 읽기 실패 (%s): %w", path, err)
+	}
+
+	var rules []ScriptRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("스크립트 파일 파싱 실패 (%s): %w", path, err)
+	}
+	return NewScriptedProvider(rules...), nil
+}
+
+func (p *ScriptedProvider) Name() string {
+	return "scripted"
+}
+
+func (p *ScriptedProvider) Generate(ctx context.Context, prompt string, opts ...Option) (*Response, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	options := resolveOptions(GenerateOptions{Model: "scripted"}, opts)
+
+	p.mu.Lock()
+	p.prompts = append(p.prompts, prompt)
+	p.mu.Unlock()
+
+	for _, rule := range p.rules {
+		if strings.Contains(prompt, rule.Match) {
+			return &Response{Text: rule.Response, Model: options.Model}, nil
+		}
+	}
+	return nil, fmt.Errorf("프롬프트에 대응하는 스크립트 응답이 없습니다")
+}
+
+// Stream 은 Generate 와 같은 응답을 공백 단위 조각으로 나눠 보냅니다.
+func (p *ScriptedProvider) Stream(ctx context.Context, prompt string, opts ...Option) (<-chan StreamChunk, error) {
+	resp, err := p.Generate(ctx, prompt, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	chunks := make(chan StreamChunk)
+	go func() {
+		defer close(chunks)
+		for _, piece := range strings.SplitAfter(resp.Text, " ") {
+			if !sendChunk(ctx, chunks, StreamChunk{Text: piece}) {
+				return
+			}
+		}
+	}()
+	return chunks, nil
+}
+
+// Prompts 는 지금까지 전달받은 프롬프트를 호출 순서대로 반환합니다.
+func (p *ScriptedProvider) Prompts() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.prompts...)
+}
@@ -0,0 +1,78 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+)
+
+// StreamChunk 는 스트리밍 응답의 조각입니다. Err 가 있으면 마지막 조각이며 채널은 곧 닫힙니다.
+// 서버가 토큰 사용량을 알려주면 Text 없이 Usage 와 Model 만 담긴 조각이 마지막에 옵니다.
+type StreamChunk struct {
+	Text  string
+	Usage *Usage
+	Model string
+	Err   error
+}
+
+// StreamingProvider 는 생성되는 텍스트를 조각 단위로 흘려보낼 수 있는 프로바이더입니다.
+// 반환된 채널은 생성이 끝나거나 ctx 가 취소되면 닫힙니다.
+type StreamingProvider interface {
+	Provider
+	Stream(ctx context.Context, prompt string, opts ...Option) (<-chan StreamChunk, error)
+}
+
+// GenerateStream 은 프로바이더가 스트리밍을 지원하면 Stream 을, 아니면 Generate 결과를 한 조각으로 돌려줍니다.
+func GenerateStream(ctx context.Context, provider Provider, prompt string, opts ...Option) (<-chan StreamChunk, error) {
+	if streaming, ok := provider.(StreamingProvider); ok {
+		return streaming.Stream(ctx, prompt, opts...)
+	}
+
+	resp, err := provider.Generate(ctx, prompt, opts...)
+	if err != nil {
+		return nil, err
+	}
+	chunks := make(chan StreamChunk, 2)
+	chunks <- StreamChunk{Text: resp.Text}
+	chunks <- StreamChunk{Usage: &resp.Usage, Model: resp.Model}
+	close(chunks)
+	return chunks, nil
+}
+
+// sendChunk 는 ctx 가 취소되지 않은 동안에만 조각을 보냅니다.
+func sendChunk(ctx context.Context, chunks chan<- StreamChunk, chunk StreamChunk) bool {
+	select {
+	case chunks <- chunk:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// readServerSentEvents 는 SSE 본문에서 "data:" 줄을 하나씩 handle 에 넘깁니다.
+// handle 이 false 를 반환하면 읽기를 멈춥니다.
+func readServerSentEvents(body io.Reader, handle func(data []byte) (bool, error)) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if !bytes.HasPrefix(line, []byte("data:")) {
+			continue
+		}
+		data := bytes.TrimSpace(bytes.TrimPrefix(line, []byte("data:")))
+		if len(data) == 0 {
+			continue
+		}
+
+		more, err := handle(data)
+		if err != nil {
+			return err
+		}
+		if !more {
+			return nil
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,111 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+)
+
+const maxJSONRepairAttempts = 2
+
+const jsonRepairPromptTemplate = `Your previous output could not be used: %s
+Return ONLY the corrected JSON that satisfies this JSON Schema, with no explanations or code fences:
+%s`
+
+// StructuredOutput 은 프로바이더에 요청할 JSON Schema 입니다.
+type StructuredOutput struct {
+	Name   string
+	Schema *JSONSchema
+}
+
+// WithJSONSchema 는 프로바이더 고유의 구조화 출력 기능(Gemini responseMimeType/스키마,
+// OpenAI response_format json_schema)으로 응답 형식을 강제합니다.
+func WithJSONSchema(name string, schema *JSONSchema) Option {
+	return func(o *GenerateOptions) { o.ResponseSchema = &StructuredOutput{Name: name, Schema: schema} }
+}
+
+// GenerateJSON 은 out 의 타입에서 스키마를 만들어 구조화 출력을 요청하고, 응답을 검증한 뒤 out 에 디코딩합니다.
+// 응답이 스키마와 맞지 않으면 오류 내용을 알려주고 최대 maxJSONRepairAttempts 번 다시 요청합니다.
+func GenerateJSON(ctx context.Context, provider Provider, prompt string, out any, opts ...Option) (*Response, error) {
+	schema := SchemaFor(out)
+	schemaName := schemaNameFor(out)
+	schemaJSON, err := json.Marshal(schema)
+	if err != nil {
+		return nil, fmt.Errorf("JSON 스키마 생성 실패: %w", err)
+	}
+
+	baseOptions := append(append([]Option(nil), opts...), WithJSONSchema(schemaName, schema))
+	history := resolveOptions(GenerateOptions{}, opts).History
+
+	currentPrompt := prompt
+	for attempt := 0; ; attempt++ {
+		attemptOptions := baseOptions
+		if attempt > 0 {
+			attemptOptions = append(append([]Option(nil), baseOptions...), WithHistory(history))
+		}
+
+		resp, err := provider.Generate(ctx, currentPrompt, attemptOptions...)
+		if err != nil {
+			return nil, err
+		}
+
+		decodeErr := decodeValidatedJSON(resp.Text, schema, out)
+		if decodeErr == nil {
+			return resp, nil
+		}
+		if attempt >= maxJSONRepairAttempts {
+			return nil, fmt.Errorf("구조화 출력 검증 실패 (%d회 시도): %w, 원본 응답: %s", attempt+1, decodeErr, resp.Text)
+		}
+
+		log.Printf("경고: %s 구조화 출력이 스키마와 맞지 않아 다시 요청합니다 (%d/%d): %v", schemaName, attempt+1, maxJSONRepairAttempts, decodeErr)
+		history = append(history,
+			Message{Role: "user", Content: currentPrompt},
+			Message{Role: "assistant", Content: resp.Text},
+		)
+		currentPrompt = fmt.Sprintf(jsonRepairPromptTemplate, decodeErr, schemaJSON)
+	}
+}
+
+// decodeValidatedJSON 은 응답 전체를 JSON 으로 해석합니다. 구조화 출력을 지원하지 않는
+// 프로바이더를 위해 앞뒤 코드 펜스만 허용하며, 본문 중간의 중괄호를 잘라내지는 않습니다.
+func decodeValidatedJSON(text string, schema *JSONSchema, out any) error {
+	raw := stripCodeFence(text)
+
+	var generic any
+	if err := json.Unmarshal([]byte(raw), &generic); err != nil {
+		return fmt.Errorf("유효한 JSON 이 아닙니다: %w", err)
+	}
+	if err := schema.Validate(generic); err != nil {
+		return err
+	}
+	if err := json.Unmarshal([]byte(raw), out); err != nil {
+		return fmt.Errorf("JSON 디코딩 실패: %w", err)
+	}
+	return nil
+}
+
+func stripCodeFence(text string) string {
+	trimmed := strings.TrimSpace(text)
+	if !strings.HasPrefix(trimmed, "```") {
+		return trimmed
+	}
+	trimmed = strings.TrimPrefix(trimmed, "```")
+	if newline := strings.Index(trimmed, "\n"); newline >= 0 {
+		trimmed = trimmed[newline+1:]
+	}
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(trimmed), "```"))
+}
+
+func schemaNameFor(v any) string {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Name() == "" {
+		return "response"
+	}
+	return t.Name()
+}
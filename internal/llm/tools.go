@@ -0,0 +1,94 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Tool 은 모델이 호출할 수 있는 함수 선언입니다. Parameters 는 인자 객체의 스키마입니다.
+type Tool struct {
+	Name        string
+	Description string
+	Parameters  *JSONSchema
+}
+
+// ToolCall 은 모델이 요청한 함수 호출입니다. ID 는 프로바이더가 호출 식별자를 줄 때만 채워집니다.
+type ToolCall struct {
+	ID        string
+	Name      string
+	Arguments json.RawMessage
+}
+
+// ToolModeNone 은 도구를 선언한 채로 모델이 도구를 호출하지 못하게 합니다.
+// 이력에 도구 호출이 남아 있는 상태에서 최종 답변만 받아야 할 때 씁니다.
+const ToolModeNone = "none"
+
+// WithTools 는 이번 호출에서 모델이 사용할 수 있는 도구를 지정합니다.
+// 도구 호출을 지원하지 않는 프로바이더는 이 옵션을 무시하고 텍스트로 답합니다.
+func WithTools(tools ...Tool) Option {
+	return func(o *GenerateOptions) { o.Tools = tools }
+}
+
+func WithToolMode(mode string) Option {
+	return func(o *GenerateOptions) { o.ToolMode = mode }
+}
+
+// ToolHandler 는 모델이 보낸 인자로 도구를 실행합니다. 반환값이 문자열이 아니면 JSON 으로 바꿔 모델에게 돌려줍니다.
+type ToolHandler func(ctx context.Context, arguments json.RawMessage) (any, error)
+
+// ToolRegistry 는 에이전트가 쓸 수 있는 도구 선언과 실행 함수를 등록 순서대로 보관합니다.
+type ToolRegistry struct {
+	tools    []Tool
+	handlers map[string]ToolHandler
+}
+
+func NewToolRegistry() *ToolRegistry {
+	return &ToolRegistry{handlers: map[string]ToolHandler{}}
+}
+
+func (r *ToolRegistry) Register(tool Tool, handler ToolHandler) {
+	if _, exists := r.handlers[tool.Name]; !exists {
+		r.tools = append(r.tools, tool)
+	}
+	r.handlers[tool.Name] = handler
+}
+
+// RegisterTool 은 인자 타입 A 에서 파라미터 스키마를 만들어 도구를 등록합니다.
+// 인자는 스키마로 검증한 뒤 A 로 디코딩해 handler 에 넘깁니다.
+func RegisterTool[A any](r *ToolRegistry, name string, description string, handler func(ctx context.Context, args A) (any, error)) {
+	var zero A
+	schema := SchemaFor(zero)
+	r.Register(Tool{Name: name, Description: description, Parameters: schema}, func(ctx context.Context, arguments json.RawMessage) (any, error) {
+		var args A
+		if err := decodeValidatedJSON(string(arguments), schema, &args); err != nil {
+			return nil, fmt.Errorf("%s 도구의 인자가 올바르지 않습니다: %w", name, err)
+		}
+		return handler(ctx, args)
+	})
+}
+
+func (r *ToolRegistry) Tools() []Tool {
+	return r.tools
+}
+
+// Call 은 도구를 실행하고 모델에게 돌려줄 문자열 결과를 만듭니다.
+func (r *ToolRegistry) Call(ctx context.Context, call ToolCall) (string, error) {
+	handler, ok := r.handlers[call.Name]
+	if !ok {
+		return "", fmt.Errorf("등록되지 않은 도구입니다: %s", call.Name)
+	}
+
+	result, err := handler(ctx, call.Arguments)
+	if err != nil {
+		return "", err
+	}
+	if text, ok := result.(string); ok {
+		return text, nil
+	}
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return "", fmt.Errorf("%s 도구 결과 인코딩 실패: %w", call.Name, err)
+	}
+	return string(encoded), nil
+}
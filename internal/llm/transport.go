@@ -0,0 +1,275 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/JCSong-89/trpg-rag-game/pkg/types"
+	"golang.org/x/time/rate"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultHTTPTimeout      = 60 * time.Second
+	defaultMaxRetries       = 3
+	defaultBaseBackoff      = 500 * time.Millisecond
+	defaultMaxBackoff       = 30 * time.Second
+	defaultBreakerThreshold = 5
+	defaultBreakerCooldown  = 30 * time.Second
+)
+
+var ErrCircuitOpen = errors.New("서킷 브레이커가 열려 있어 요청을 보내지 않습니다")
+
+// NewHTTPClient 는 모든 외부 모델 호출이 공유하는 HTTP 클라이언트를 만듭니다.
+// 요청마다 응답 헤더 타임아웃, 429/5xx 지수 백오프 재시도(Retry-After 우선),
+// 토큰 버킷 속도 제한, 연속 실패 시 서킷 브레이커가 적용됩니다.
+// FixtureMode 가 설정되면 가장 바깥에서 요청/응답을 기록하거나 기록된 응답을 재생합니다.
+func NewHTTPClient(name string, cfg types.HTTPConfig) (*http.Client, error) {
+	cfg = withHTTPDefaults(cfg)
+
+	var limiter *rate.Limiter
+	if cfg.RatePerSecond > 0 {
+		limiter = rate.NewLimiter(rate.Limit(cfg.RatePerSecond), max(cfg.Burst, 1))
+	}
+
+	transport, err := newFixtureTransport(cfg.FixtureMode, cfg.FixtureDir, &resilientTransport{
+		name:    name,
+		base:    http.DefaultTransport,
+		cfg:     cfg,
+		limiter: limiter,
+		breaker: &circuitBreaker{threshold: cfg.BreakerThreshold, cooldown: cfg.BreakerCooldown},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{Transport: transport}, nil
+}
+
+func withHTTPDefaults(cfg types.HTTPConfig) types.HTTPConfig {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultHTTPTimeout
+	}
+	if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	} else if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = defaultMaxRetries
+	}
+	if cfg.BaseBackoff <= 0 {
+		cfg.BaseBackoff = defaultBaseBackoff
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = defaultMaxBackoff
+	}
+	if cfg.BreakerThreshold == 0 {
+		cfg.BreakerThreshold = defaultBreakerThreshold
+	}
+	if cfg.BreakerCooldown <= 0 {
+		cfg.BreakerCooldown = defaultBreakerCooldown
+	}
+	return cfg
+}
+
+type resilientTransport struct {
+	name    string
+	base    http.RoundTripper
+	cfg     types.HTTPConfig
+	limiter *rate.Limiter
+	breaker *circuitBreaker
+}
+
+func (t *resilientTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	allowed, probe := t.breaker.allow()
+	if !allowed {
+		return nil, fmt.Errorf("%s: %w", t.name, ErrCircuitOpen)
+	}
+	if probe {
+		// 시험 요청이 취소 등으로 성공/실패 판정 없이 끝나도 다음 요청이 시험할 수 있게 합니다.
+		defer t.breaker.releaseProbe()
+	}
+
+	for attempt := 0; ; attempt++ {
+		if t.limiter != nil {
+			if err := t.limiter.Wait(req.Context()); err != nil {
+				return nil, err
+			}
+		}
+
+		attemptReq, err := rewindRequest(req, attempt)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := t.roundTripWithTimeout(attemptReq)
+		retryable := err != nil || isRetryableStatus(resp.StatusCode)
+		if !retryable {
+			t.breaker.success()
+			return resp, nil
+		}
+		if req.Context().Err() != nil {
+			if resp != nil {
+				resp.Body.Close()
+			}
+			return nil, req.Context().Err()
+		}
+
+		if attempt >= t.cfg.MaxRetries {
+			t.breaker.failure()
+			return resp, err
+		}
+
+		delay := t.backoff(attempt, resp)
+		if err != nil {
+			log.Printf("경고: %s 요청 실패, %v 후 재시도합니다 (%d/%d): %v", t.name, delay, attempt+1, t.cfg.MaxRetries, err)
+		} else {
+			log.Printf("경고: %s 응답 상태 %d, %v 후 재시도합니다 (%d/%d)", t.name, resp.StatusCode, delay, attempt+1, t.cfg.MaxRetries)
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// roundTripWithTimeout 은 응답 헤더를 받을 때까지만 타임아웃을 적용합니다.
+// 본문은 스트리밍 응답일 수 있으므로 헤더 이후에는 호출자의 컨텍스트만 따릅니다.
+func (t *resilientTransport) roundTripWithTimeout(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithCancel(req.Context())
+	timer := time.AfterFunc(t.cfg.Timeout, cancel)
+
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if !timer.Stop() && err != nil {
+		cancel()
+		return nil, fmt.Errorf("%v 안에 응답 헤더를 받지 못했습니다: %w", t.cfg.Timeout, err)
+	}
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+func (t *resilientTransport) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if delay, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			return min(delay, t.cfg.MaxBackoff)
+		}
+	}
+
+	delay := t.cfg.BaseBackoff << attempt
+	if delay <= 0 || delay > t.cfg.MaxBackoff {
+		delay = t.cfg.MaxBackoff
+	}
+	jitter := time.Duration(rand.Int63n(int64(delay)/2 + 1))
+	return delay/2 + jitter
+}
+
+func rewindRequest(req *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 0 || req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+	if req.GetBody == nil {
+		return nil, fmt.Errorf("요청 본문을 다시 읽을 수 없어 재시도할 수 없습니다")
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, fmt.Errorf("요청 본문 복제 실패: %w", err)
+	}
+	clone := req.Clone(req.Context())
+	clone.Body = body
+	return clone, nil
+}
+
+func isRetryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
+
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0), true
+	}
+	return 0, false
+}
+
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
+
+// circuitBreaker 는 재시도까지 모두 실패한 호출이 threshold 번 연속되면 cooldown 동안 요청을 차단합니다.
+// cooldown 이 지나면 요청 하나를 시험 삼아 통과시키고, 성공하면 다시 닫힙니다. 시험 요청이 취소되면 판정 없이 다음 요청이 다시 시험합니다.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+// allow 는 요청을 보내도 되는지와, 그 요청이 차단 상태를 시험하는 요청인지를 돌려줍니다.
+func (b *circuitBreaker) allow() (allowed bool, probe bool) {
+	if b.threshold < 0 {
+		return true, false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return true, false
+	}
+	if time.Now().Before(b.openUntil) || b.probing {
+		return false, false
+	}
+	b.probing = true
+	return true, true
+}
+
+// releaseProbe 는 시험 요청이 끝났음을 알립니다. 실패 횟수와 차단 시간은 바꾸지 않습니다.
+func (b *circuitBreaker) releaseProbe() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+func (b *circuitBreaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+	b.probing = false
+}
+
+func (b *circuitBreaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	b.probing = false
+	if b.threshold >= 0 && b.failures >= b.threshold {
+		b.openUntil = time.Now().Add(b.cooldown)
+	}
+}
@@ -0,0 +1,211 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"github.com/JCSong-89/trpg-rag-game/pkg/types"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// UsageTotals 는 호출 횟수, 토큰 수, 추정 비용(USD)의 합계입니다.
+type UsageTotals struct {
+	Calls        int
+	InputTokens  int
+	OutputTokens int
+	TotalTokens  int
+	CostUSD      float64
+}
+
+func (t *UsageTotals) add(usage Usage, cost float64) {
+	t.Calls++
+	t.InputTokens += usage.InputTokens
+	t.OutputTokens += usage.OutputTokens
+	t.TotalTokens += usage.TotalTokens
+	t.CostUSD += cost
+}
+
+func (t UsageTotals) sub(earlier UsageTotals) UsageTotals {
+	return UsageTotals{
+		Calls:        t.Calls - earlier.Calls,
+		InputTokens:  t.InputTokens - earlier.InputTokens,
+		OutputTokens: t.OutputTokens - earlier.OutputTokens,
+		TotalTokens:  t.TotalTokens - earlier.TotalTokens,
+		CostUSD:      t.CostUSD - earlier.CostUSD,
+	}
+}
+
+type UsageReport struct {
+	Name    string
+	ByStage map[string]UsageTotals
+	ByModel map[string]UsageTotals
+	Total   UsageTotals
+}
+
+func (r UsageReport) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("[%s] 호출 %d회, 입력 %d / 출력 %d / 합계 %d 토큰, 추정 비용 $%.6f",
+		r.Name, r.Total.Calls, r.Total.InputTokens, r.Total.OutputTokens, r.Total.TotalTokens, r.Total.CostUSD))
+
+	writeTotals := func(title string, totals map[string]UsageTotals) {
+		keys := make([]string, 0, len(totals))
+		for key := range totals {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			t := totals[key]
+			sb.WriteString(fmt.Sprintf("\n  - %s %s: 호출 %d회, 입력 %d / 출력 %d 토큰, $%.6f",
+				title, key, t.Calls, t.InputTokens, t.OutputTokens, t.CostUSD))
+		}
+	}
+	writeTotals("단계", r.ByStage)
+	writeTotals("모델", r.ByModel)
+	return sb.String()
+}
+
+// Since 는 같은 UsageTracker 에서 앞서 받은 earlier 이후에 늘어난 사용량만 name 이라는 이름으로 돌려줍니다.
+// 세션 전체를 모으는 트래커에서 질문 하나의 사용량을 떼어 낼 때 씁니다.
+func (r UsageReport) Since(earlier UsageReport, name string) UsageReport {
+	since := func(current map[string]UsageTotals, earlier map[string]UsageTotals) map[string]UsageTotals {
+		totals := map[string]UsageTotals{}
+		for key, t := range current {
+			if diff := t.sub(earlier[key]); diff.Calls > 0 {
+				totals[key] = diff
+			}
+		}
+		return totals
+	}
+	return UsageReport{
+		Name:    name,
+		ByStage: since(r.ByStage, earlier.ByStage),
+		ByModel: since(r.ByModel, earlier.ByModel),
+		Total:   r.Total.sub(earlier.Total),
+	}
+}
+
+// UsageTracker 는 한 번의 질의나 적재 작업 동안 발생한 LLM 호출의 토큰 사용량을 모읍니다.
+type UsageTracker struct {
+	name   string
+	prices map[string]types.ModelPrice
+
+	mu      sync.Mutex
+	byStage map[string]UsageTotals
+	byModel map[string]UsageTotals
+	total   UsageTotals
+}
+
+func NewUsageTracker(name string, prices map[string]types.ModelPrice) *UsageTracker {
+	return &UsageTracker{
+		name:    name,
+		prices:  prices,
+		byStage: map[string]UsageTotals{},
+		byModel: map[string]UsageTotals{},
+	}
+}
+
+func (t *UsageTracker) Record(stage string, model string, usage Usage) {
+	if stage == "" {
+		stage = "unspecified"
+	}
+	cost := t.cost(model, usage)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stageTotals := t.byStage[stage]
+	stageTotals.add(usage, cost)
+	t.byStage[stage] = stageTotals
+
+	modelTotals := t.byModel[model]
+	modelTotals.add(usage, cost)
+	t.byModel[model] = modelTotals
+
+	t.total.add(usage, cost)
+}
+
+func (t *UsageTracker) Report() UsageReport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	report := UsageReport{
+		Name:    t.name,
+		ByStage: make(map[string]UsageTotals, len(t.byStage)),
+		ByModel: make(map[string]UsageTotals, len(t.byModel)),
+		Total:   t.total,
+	}
+	for k, v := range t.byStage {
+		report.ByStage[k] = v
+	}
+	for k, v := range t.byModel {
+		report.ByModel[k] = v
+	}
+	return report
+}
+
+// cost 는 모델 이름이 정확히 같거나 가장 길게 겹치는 접두사를 가진 가격으로 비용을 계산합니다.
+// 응답의 모델 이름이 "gemini-2.0-flash-001" 처럼 버전 접미사를 달고 오는 경우를 위해서입니다.
+func (t *UsageTracker) cost(model string, usage Usage) float64 {
+	price, ok := t.prices[model]
+	if !ok {
+		matched := ""
+		for name, candidate := range t.prices {
+			if strings.HasPrefix(model, name) && len(name) > len(matched) {
+				matched, price, ok = name, candidate, true
+			}
+		}
+	}
+	if !ok {
+		return 0
+	}
+	return float64(usage.InputTokens)/1e6*price.InputPerMillion + float64(usage.OutputTokens)/1e6*price.OutputPerMillion
+}
+
+// MeteredProvider 는 감싼 프로바이더의 모든 호출 사용량을 UsageTracker 에 기록합니다.
+type MeteredProvider struct {
+	inner   Provider
+	tracker *UsageTracker
+}
+
+func NewMeteredProvider(inner Provider, tracker *UsageTracker) *MeteredProvider {
+	return &MeteredProvider{inner: inner, tracker: tracker}
+}
+
+func (p *MeteredProvider) Name() string {
+	return p.inner.Name()
+}
+
+func (p *MeteredProvider) Generate(ctx context.Context, prompt string, opts ...Option) (*Response, error) {
+	resp, err := p.inner.Generate(ctx, prompt, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Cached {
+		p.tracker.Record(resolveOptions(GenerateOptions{}, opts).Stage, resp.Model, resp.Usage)
+	}
+	return resp, nil
+}
+
+func (p *MeteredProvider) Stream(ctx context.Context, prompt string, opts ...Option) (<-chan StreamChunk, error) {
+	upstream, err := GenerateStream(ctx, p.inner, prompt, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	stage := resolveOptions(GenerateOptions{}, opts).Stage
+
+	chunks := make(chan StreamChunk)
+	go func() {
+		defer close(chunks)
+		for chunk := range upstream {
+			if chunk.Usage != nil {
+				p.tracker.Record(stage, chunk.Model, *chunk.Usage)
+			}
+			if !sendChunk(ctx, chunks, chunk) {
+				return
+			}
+		}
+	}()
+	return chunks, nil
+}
@@ -0,0 +1,30 @@
+package llm
+
+import "testing"
+
+func TestUsageReportSince(t *testing.T) {
+	tracker := NewUsageTracker("세션", nil)
+	tracker.Record(StageQueryNER, "m1", Usage{InputTokens: 10, OutputTokens: 5, TotalTokens: 15})
+	before := tracker.Report()
+
+	tracker.Record(StageQueryNER, "m1", Usage{InputTokens: 20, OutputTokens: 2, TotalTokens: 22})
+	tracker.Record(StageFinalAnswer, "m2", Usage{InputTokens: 7, OutputTokens: 3, TotalTokens: 10})
+
+	got := tracker.Report().Since(before, "질문 2")
+	if got.Name != "질문 2" {
+		t.Errorf("Name = %q, want 질문 2", got.Name)
+	}
+	if want := (UsageTotals{Calls: 2, InputTokens: 27, OutputTokens: 5, TotalTokens: 32}); got.Total != want {
+		t.Errorf("Total = %+v, want %+v", got.Total, want)
+	}
+	if want := (UsageTotals{Calls: 1, InputTokens: 20, OutputTokens: 2, TotalTokens: 22}); got.ByStage[StageQueryNER] != want {
+		t.Errorf("ByStage[%s] = %+v, want %+v", StageQueryNER, got.ByStage[StageQueryNER], want)
+	}
+	if len(got.ByModel) != 2 {
+		t.Errorf("ByModel = %v, want m1 and m2", got.ByModel)
+	}
+
+	if empty := tracker.Report().Since(tracker.Report(), "빈 질문"); empty.Total.Calls != 0 || len(empty.ByStage) != 0 {
+		t.Errorf("Since(self) = %+v, want no usage", empty)
+	}
+}
@@ -0,0 +1,72 @@
+package prompt
+
+import (
+	"fmt"
+	"github.com/JCSong-89/trpg-rag-game/pkg/ontology"
+	"sort"
+	"strings"
+)
+
+// OntologyGuidelineTemplate 은 추출 프롬프트에 넣는 스키마 안내입니다. 차례로 라벨 목록, 관계 타입 목록, 스키마 밖 항목 처리 안내가 들어갑니다.
+const OntologyGuidelineTemplate = `
+**Schema Guideline:**
+- Use ONLY the following entity labels, written exactly as shown. Only use the listed properties, with the given value types.
+%s
+- Use ONLY the following relation types, written exactly as shown. "(A -> B)" means the source entity must have label A and the target entity label B.
+%s
+%s
+`
+
+// OntologyGuideline 은 온톨로지를 추출 프롬프트에 넣을 안내문으로 바꿉니다. schema 가 nil 이면 빈 문자열입니다.
+func OntologyGuideline(schema *ontology.Ontology) string {
+	if schema == nil {
+		return ""
+	}
+
+	var labels []string
+	for _, label := range schema.Labels {
+		line := "  - " + label.Name
+		if label.Description != "" {
+			line += ": " + label.Description
+		}
+		if len(label.Properties) > 0 {
+			var properties []string
+			for name, propertyType := range label.Properties {
+				properties = append(properties, fmt.Sprintf("%s (%s)", name, propertyType))
+			}
+			sort.Strings(properties)
+			line += " Properties: " + strings.Join(properties, ", ")
+		}
+		labels = append(labels, line)
+	}
+
+	var relations []string
+	for _, relation := range schema.Relations {
+		line := fmt.Sprintf("  - %s (%s -> %s)", relation.Name, labelList(relation.Domain), labelList(relation.Range))
+		if relation.Description != "" {
+			line += ": " + relation.Description
+		}
+		relations = append(relations, line)
+	}
+
+	var fallbacks []string
+	if schema.DefaultLabel != "" {
+		fallbacks = append(fallbacks, fmt.Sprintf("- If an entity fits none of the labels, use the label %q.", schema.DefaultLabel))
+	}
+	if schema.DefaultRelation != "" {
+		fallbacks = append(fallbacks, fmt.Sprintf("- If a relation fits none of the types, use the type %q.", schema.DefaultRelation))
+	}
+	if len(fallbacks) < 2 {
+		fallbacks = append(fallbacks, "- Leave out anything that does not fit the schema instead of inventing new labels or relation types.")
+	}
+
+	return fmt.Sprintf(OntologyGuidelineTemplate, strings.Join(labels, "\n"), strings.Join(relations, "\n"), strings.Join(fallbacks, "\n"))
+}
+
+// labelList 는 Domain/Range 를 "A|B" 로 적습니다. 비어 있으면 아무 라벨이나 된다는 뜻으로 "Any" 입니다.
+func labelList(labels []string) string {
+	if len(labels) == 0 {
+		return "Any"
+	}
+	return strings.Join(labels, "|")
+}
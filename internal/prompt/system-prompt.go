@@ -1,6 +1,10 @@
 package prompt
 
-var SystemPromt = `
+import "fmt"
+
+// ExtractionPromptTemplate 은 문서 하나에서 엔티티와 관계를 추출하는 프롬프트입니다.
+// 첫 번째 %s 에는 스키마 안내(OntologyGuideline, 없으면 빈 문자열), 두 번째 %s 에는 문서 본문이 들어갑니다.
+var ExtractionPromptTemplate = `
 You are a data architect who extracts structured data from text.
 From the given text, extract all entities and the relationships between them, paying close attention to the reasons and motivations behind events.
 
@@ -10,6 +14,7 @@ Your output MUST be a single, valid JSON object with two keys: "entities" and "r
 **Entities Guideline:**
 - "entities" should be an array of objects. Each object must have "ID", "Name", "Label", and "Properties".
 - For entities of "Event" label, if the text describes a reason for the event, add a "reason" key to its "Properties".
+- If the text refers to the same entity by other names (nicknames, short forms, spellings in another language), list them in an optional "Aliases" array and use a single entity for all of them.
 
 **Relations Guideline:**
 - "relations" should be an array of objects. Each object must have "SourceName", "TargetName", and "Type".
@@ -18,13 +23,19 @@ Your output MUST be a single, valid JSON object with two keys: "entities" and "r
 
 **Identifier Guideline:**
 - The "ID" for entities and the "SourceName"/"TargetName" for relations should be a consistent, snake_case identifier.
-
+%s
 **Text to process:**
-2025년 8월 3일 토트넘 핫스퍼의 축구선수인 손흥민은 팀을 떠나기로 결정했다. 그동안 178골 107 어시스트를 기록한 이 한국인 선수는 대한민국 국가대표 주장이자 토트넘 핫스퍼의 주장이다. 그는 2015년 처음 토트넘 핫스퍼에 이적하였고 마지막 시즌인 24/25시즌에 유로파 대회를 우승하여 팀에게 17년만에 우승컵을 안겨주었다. 그는 미국 1부 리그인 MLS의 서부리그인 LA FC로 이적을 하기로 결정하였고, 2025년 8월 8일 입단을 완료하였다. 그가 LA FC로 이적을 결정한 이유는 다음과 같다.
-1. 2026년 월드컵은 미국에서 열린다. 이번 월드컵을 선수 생활 중 마지막으로 참가한다고 생각한 손흥민은 최상의 결과를 위해 미리 미국으로 이적했다고 밝혔다.
-2. 많은 팀 중 LA FC의 회장이 직접 전화를 걸어 포부와 미래 그리고 기대와 처우에 대해서 감명깊게 대화한 것이 이적의 주요 포인트였다고 한다.
+%s
 `
 
+// SampleDocumentText 는 SystemPromt 에 넣는 예시 문서입니다. ingest 는 이 문서를 적재하지 않습니다.
+const SampleDocumentText = `2025년 8월 3일 토트넘 핫스퍼의 축구선수인 손흥민은 팀을 떠나기로 결정했다. 그동안 178골 107 어시스트를 기록한 이 한국인 선수는 대한민국 국가대표 주장이자 토트넘 핫스퍼의 주장이다. 그는 2015년 처음 토트넘 핫스퍼에 이적하였고 마지막 시즌인 24/25시즌에 유로파 대회를 우승하여 팀에게 17년만에 우승컵을 안겨주었다. 그는 미국 1부 리그인 MLS의 서부리그인 LA FC로 이적을 하기로 결정하였고, 2025년 8월 8일 입단을 완료하였다. 그가 LA FC로 이적을 결정한 이유는 다음과 같다.
+1. 2026년 월드컵은 미국에서 열린다. 이번 월드컵을 선수 생활 중 마지막으로 참가한다고 생각한 손흥민은 최상의 결과를 위해 미리 미국으로 이적했다고 밝혔다.
+2. 많은 팀 중 LA FC의 회장이 직접 전화를 걸어 포부와 미래 그리고 기대와 처우에 대해서 감명깊게 대화한 것이 이적의 주요 포인트였다고 한다.`
+
+// SystemPromt 는 예시 문서를 넣은 추출 프롬프트입니다.
+var SystemPromt = fmt.Sprintf(ExtractionPromptTemplate, "", SampleDocumentText)
+
 /*
 * 받은 결과
 {
@@ -170,6 +181,54 @@ You are a helpful AI assistant answering questions based on the context provided
 Your task is to synthesize the information in the 'Context' section to answer the 'User's Question'.
 Answer ONLY with the information provided in the context. Do not use any of your prior knowledge.
 If the context does not contain the answer, say that you cannot find the answer in the provided information.
+When the context includes source excerpts, cite the source id in square brackets (e.g. [rules.md#chunk-3]) after each fact it supports, and quote the excerpt word for word when the exact wording matters.
+Answer in Korean.
+
+---
+**[Context from Knowledge Graph]**
+%s
+---
+**[User's Question]**
+%s
+`
+const ConversationSummaryPromptTemplate = `
+You are maintaining the memory of a long conversation between a user and an assistant.
+Merge the existing summary and the new turns below into a single concise summary.
+Keep every person, team, event, date and number that was mentioned, and note what the user was asking about.
+Write the summary in Korean. Output ONLY the summary text.
+
+---
+**[Existing Summary]**
+%s
+---
+**[New Turns]**
+%s
+---
+`
+
+const FollowUpRewritePromptTemplate = `
+You rewrite follow-up questions so they can be understood without the conversation.
+Using the conversation below, replace pronouns and vague references in the Follow-up Question with the entities they refer to.
+If the question is already self-contained, return it unchanged. Keep the language of the question.
+
+Your output MUST be a JSON object like this: {"query": "rewritten question"}
+
+---
+**[Conversation]**
+%s
+---
+**[Follow-up Question]**
+"%s"
+---
+`
+
+const GameMasterPromptTemplate = `
+You are the game master of a tabletop RPG whose world is stored in a knowledge graph.
+Answer the 'User's Question' using the 'Context' below. If the context is not enough, call the available tools:
+look up entities by name, search for entities by meaning, expand multi-hop relationships, quote the source text an entity was extracted from, or roll dice when the player asks for a check or an action with an uncertain outcome.
+Base every fact on the context or on tool results. Do not invent facts about the world.
+Cite the source id in square brackets (e.g. [rules.md#chunk-3]) after each fact that comes from a source excerpt, and quote rules word for word when the exact wording matters.
+If you still cannot find the answer, say that you cannot find it in the provided information.
 Answer in Korean.
 
 ---
@@ -178,4 +237,4 @@ Answer in Korean.
 ---
 **[User's Question]**
 %s
-`
\ No newline at end of file
+`
@@ -3,108 +3,216 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/JCSong-89/trpg-rag-game/internal/db"
 	"github.com/JCSong-89/trpg-rag-game/internal/llm"
 	"github.com/JCSong-89/trpg-rag-game/pkg/types"
 	"github.com/google/uuid"
-	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
-	"github.com/qdrant/go-client/qdrant"
 	"log"
-	"os"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
 )
 
-func insertNodeToNeo4j(ctx context.Context, tx neo4j.ManagedTransaction, entity types.Entity, qdrantId string) error {
-	params := map[string]any{
-		"entityId": entity.ID,
-		"name":     entity.Name,
-		"qdrantId": qdrantId,
-	}
-	for k, v := range entity.Properties {
-		params[k] = v
-	}
+const (
+	defaultIngestBatchSize   = 32
+	defaultIngestConcurrency = 4
+)
 
-	safeLabel := strings.ReplaceAll(entity.Label, " ", "_")
-	query := fmt.Sprintf("CREATE (e:%s) SET e = $props", safeLabel)
+func upsertEntityVectors(ctx context.Context, vectors db.VectorStore, collectionName string, entities []types.Entity, pointIDs []string) error {
+	var points []db.VectorPoint
+	for i, entity := range entities {
+		if entity.Embedding == nil {
+			continue
+		}
+		points = append(points, db.VectorPoint{
+			ID:      pointIDs[i],
+			Vector:  entity.Embedding,
+			Payload: entityPayload(entity),
+		})
+	}
+	return vectors.Upsert(ctx, collectionName, points)
+}
 
-	_, err := tx.Run(ctx, query, map[string]any{"props": params})
-	if err != nil {
-		return fmt.Errorf("Neo4j 노드 생성 실패 (%s): %w", entity.Name, err)
+// entityPayload 는 엔티티 벡터와 함께 저장하는 payload 입니다. sources 는 엔티티가 언급된 청크 id 목록으로,
+// VectorFilter 로 특정 청크에 나온 엔티티만 검색할 때 씁니다. entityId, label, aliases 는 엔티티 해소에서 후보를 비교할 때 씁니다.
+func entityPayload(entity types.Entity) map[string]any {
+	payload := map[string]any{"name": entity.Name, "entityId": entity.ID, "label": entity.Label}
+	if len(entity.Aliases) > 0 {
+		payload["aliases"] = anyStrings(entity.Aliases)
 	}
-	return nil
+	if len(entity.Sources) > 0 {
+		payload["sources"] = anyStrings(entity.Sources)
+	}
+	return payload
 }
 
-func upsetVectorToQuadrant(ctx context.Context, qdrantClient qdrant.PointsClient, collectionName string, entity types.Entity, pointID string) error {
-	if entity.Embedding == nil {
-		return nil
-	}
-	isWaitOption := true
-	_, err := qdrantClient.Upsert(ctx, &qdrant.UpsertPoints{
-		CollectionName: collectionName, Wait: &isWaitOption,
-		Points: []*qdrant.PointStruct{
-			{
-				Id:      &qdrant.PointId{PointIdOptions: &qdrant.PointId_Uuid{Uuid: pointID}},
-				Vectors: &qdrant.Vectors{VectorsOptions: &qdrant.Vectors_Vector{Vector: &qdrant.Vector{Data: entity.Embedding}}},
-				Payload: map[string]*qdrant.Value{"name": {Kind: &qdrant.Value_StringValue{StringValue: entity.Name}}},
-			},
-		},
-	})
-	if err != nil {
-		return fmt.Errorf("Quadrant 포인트 업서트 실패 (%s): %w", entity.Name, err)
+func anyStrings(values []string) []any {
+	list := make([]any, len(values))
+	for i, value := range values {
+		list[i] = value
 	}
-	return nil
+	return list
 }
 
-func processSingleEntity(ctx context.Context, session neo4j.SessionWithContext, quadrantClient qdrant.PointsClient, collectionName string, entity types.Entity, qdrantPointID string) error {
+// buildEmbeddingText 는 이름과 속성을 "key: value" 형태로 이어 붙인 임베딩 입력을 만듭니다.
+// 같은 엔티티가 항상 같은 텍스트가 되도록 속성 키를 정렬합니다.
+func buildEmbeddingText(entity types.Entity) string {
+	keys := make([]string, 0, len(entity.Properties))
+	for key := range entity.Properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var propStrings []string
+	for _, key := range keys {
+		propStrings = append(propStrings, fmt.Sprintf("%s: %v", key, entity.Properties[key]))
+	}
 
-	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
-		if err := insertNodeToNeo4j(ctx, tx, entity, qdrantPointID); err != nil {
-			return nil, err
+	textToEmbed := entity.Name
+	if len(propStrings) > 0 {
+		textToEmbed += ", " + strings.Join(propStrings, ", ")
+	}
+	return textToEmbed
+}
+
+// dedupeEntities 는 같은 ID 의 엔티티를 처음 나온 자리에 하나로 합칩니다. 속성은 policy 에 따라 합치고, 별칭과 출처는 모두 모읍니다.
+// 합쳐진 엔티티는 임베딩 텍스트가 바뀌므로 임베딩을 지워 다시 계산하게 합니다.
+func dedupeEntities(entities []types.Entity, policy types.ConflictPolicy) []types.Entity {
+	indexByID := make(map[string]int, len(entities))
+	deduped := make([]types.Entity, 0, len(entities))
+	for _, entity := range entities {
+		index, exists := indexByID[entity.ID]
+		if !exists {
+			indexByID[entity.ID] = len(deduped)
+			deduped = append(deduped, entity)
+			continue
+		}
+		existing := &deduped[index]
+		existing.Properties = db.MergeProperties(existing.Properties, entity.Properties, policy)
+		existing.Sources = db.AppendUnique(existing.Sources, entity.Sources...)
+		existing.Aliases = db.AppendUnique(existing.Aliases, entity.Aliases...)
+		existing.Embedding = nil
+		if policy != types.ConflictKeep {
+			existing.Aliases = db.AppendUnique(existing.Aliases, existing.Name)
+			existing.Name, existing.Label = entity.Name, entity.Label
+		} else {
+			existing.Aliases = db.AppendUnique(existing.Aliases, entity.Name)
 		}
+		existing.Aliases = slices.DeleteFunc(existing.Aliases, func(alias string) bool { return alias == "" || alias == existing.Name })
+	}
+	return deduped
+}
 
-		if err := upsetVectorToQuadrant(ctx, quadrantClient, collectionName, entity, qdrantPointID); err != nil {
-			return nil, err
+// processEntityBatch 는 배치에서 임베딩이 없는 엔티티만 한 번의 임베딩 호출로 벡터화한 뒤
+// 하나의 벡터 업서트와 하나의 그래프 저장소 쓰기로 저장합니다.
+// 이미 저장된 엔티티는 기존 포인트 id 를 그대로 써서 벡터를 덮어쓰고, payload 의 출처에는 이전에 언급된 청크도 남깁니다.
+func processEntityBatch(ctx context.Context, graph db.GraphStore, vectors db.VectorStore, embedder llm.Embedder, collectionName string, batch []types.Entity, texts []string, policy types.ConflictPolicy) error {
+	var pending []int
+	var pendingTexts []string
+	for i := range batch {
+		if batch[i].Embedding == nil {
+			pending = append(pending, i)
+			pendingTexts = append(pendingTexts, texts[i])
+		}
+	}
+	if len(pending) > 0 {
+		embeddings, err := embedder.Embed(ctx, pendingTexts)
+		if err != nil {
+			return fmt.Errorf("임베딩 생성 실패: %w", err)
+		}
+		if len(embeddings) != len(pending) {
+			return fmt.Errorf("임베딩 개수가 입력과 다릅니다 (입력: %d, 응답: %d)", len(pending), len(embeddings))
 		}
-		return nil, nil
-	})
+		for j, i := range pending {
+			batch[i].Embedding = embeddings[j]
+		}
+	}
 
+	entityIDs := make([]string, len(batch))
+	for i, entity := range batch {
+		entityIDs[i] = entity.ID
+	}
+	existingPointIDs, err := graph.PointIDs(ctx, entityIDs)
+	if err != nil {
+		return err
+	}
+	existingSources, err := graph.EntitySources(ctx, entityIDs)
 	if err != nil {
-		return fmt.Errorf("단일 엔티티 처리 트랜잭션 실패 (%s): %w", entity.Name, err)
+		return err
+	}
+
+	pointIDs := make([]string, len(batch))
+	for i := range batch {
+		batch[i].Sources = db.AppendUnique(existingSources[batch[i].ID], batch[i].Sources...)
+		pointIDs[i] = existingPointIDs[batch[i].ID]
+		if pointIDs[i] == "" {
+			pointIDs[i] = uuid.New().String()
+		}
 	}
 
-	log.Printf("... 엔티티 '%s' 처리 완료 (Neo4j & Qdrant)", entity.Name)
+	if err := upsertEntityVectors(ctx, vectors, collectionName, batch, pointIDs); err != nil {
+		return err
+	}
+
+	nodes := make([]db.GraphNode, len(batch))
+	for i, entity := range batch {
+		nodes[i] = db.GraphNode{Entity: entity, PointID: pointIDs[i]}
+	}
+	if err := graph.UpsertNodes(ctx, nodes, policy); err != nil {
+		return fmt.Errorf("엔티티 배치 그래프 저장 실패: %w", err)
+	}
 	return nil
 }
 
-func ProcessAndStoreEntities(ctx context.Context, driver neo4j.DriverWithContext, quadrantClient qdrant.PointsClient, collectionName string, entities []types.Entity) {
-	session := driver.NewSession(ctx, neo4j.SessionConfig{})
-	defer session.Close(ctx)
-	hfAPIToken := os.Getenv("HUGGING_TOKEN")
+// ProcessAndStoreEntities 는 엔티티를 배치로 나눠 동시에 저장합니다. 실패한 배치가 있어도 나머지 배치는 끝까지 저장하고,
+// 실패한 배치의 오류를 모두 모아 돌려줍니다.
+func ProcessAndStoreEntities(ctx context.Context, graph db.GraphStore, vectors db.VectorStore, embedder llm.Embedder, collectionName string, entities []types.Entity, ingestCfg types.IngestConfig) error {
+	batchSize := ingestCfg.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultIngestBatchSize
+	}
+	concurrency := ingestCfg.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultIngestConcurrency
+	}
+	entities = dedupeEntities(entities, ingestCfg.ConflictPolicy)
 
-	for _, entity := range entities {
-		qdrantPointID := uuid.New().String()
+	texts := make([]string, len(entities))
+	for i, entity := range entities {
+		texts[i] = buildEmbeddingText(entity)
+	}
 
-		var propStrings []string
-		for key, value := range entity.Properties {
-			propStrings = append(propStrings, fmt.Sprintf("%s: %v", key, value))
-		}
+	var wg sync.WaitGroup
+	var errsMu sync.Mutex
+	var errs []error
+	semaphore := make(chan struct{}, concurrency)
 
-		textToEmbed := entity.Name
-		if len(propStrings) > 0 {
-			textToEmbed += ", " + strings.Join(propStrings, ", ")
-		}
+	for start := 0; start < len(entities); start += batchSize {
+		end := min(start+batchSize, len(entities))
+		batch := append([]types.Entity(nil), entities[start:end]...)
 
-		embeddings, err := llm.GetBGEEmbeddings([]string{textToEmbed}, hfAPIToken)
-		if err != nil {
-			log.Printf("경고: '%s'의 임베딩 생성 실패: %v", entity.ID, err)
-			continue
-		}
-		entity.Embedding = embeddings[0]
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(start int, batch []types.Entity, texts []string) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
 
-		if err := processSingleEntity(ctx, session, quadrantClient, collectionName, entity, qdrantPointID); err != nil {
-			log.Printf("에러: 엔티티 처리 중 최종 오류 발생 (%s): %v", entity.Name, err)
-		}
+			if err := processEntityBatch(ctx, graph, vectors, embedder, collectionName, batch, texts, ingestCfg.ConflictPolicy); err != nil {
+				log.Printf("에러: 엔티티 배치 처리 중 오류 발생 (%d~%d번째): %v", start, start+len(batch)-1, err)
+				errsMu.Lock()
+				errs = append(errs, fmt.Errorf("엔티티 배치 처리 실패 (%d~%d번째): %w", start, start+len(batch)-1, err))
+				errsMu.Unlock()
+				return
+			}
+			log.Printf("... 엔티티 %d개 처리 완료 (그래프 & 벡터, %d~%d번째)", len(batch), start, start+len(batch)-1)
+		}(start, batch, texts[start:end])
 	}
+
+	wg.Wait()
+	return errors.Join(errs...)
 }
 
 func ParseAndRefineResponse(jsonString string) ([]types.Entity, []types.Relation, error) {
@@ -115,46 +223,42 @@ func ParseAndRefineResponse(jsonString string) ([]types.Entity, []types.Relation
 
 	return parsedResult.Entities, parsedResult.Relations, nil
 }
-func InsertRelations(ctx context.Context, driver neo4j.DriverWithContext, relations []types.Relation) {
-	session := driver.NewSession(ctx, neo4j.SessionConfig{})
-	defer session.Close(ctx)
-
-	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
-		for _, rel := range relations {
-			safeRelationType := strings.ReplaceAll(rel.Type, " ", "_")
-			query := `
-                MATCH (a {entityId: $sourceId})
-                MATCH (b {entityId: $targetId})
-                CREATE (a)-[r:%s]->(b)
-                RETURN type(r) AS created_relation_type
-            `
-			formattedQuery := fmt.Sprintf(query, safeRelationType)
-
-			result, err := tx.Run(ctx, formattedQuery, map[string]any{
-				"sourceId": rel.SourceName,
-				"targetId": rel.TargetName,
-			})
-			if err != nil {
-				// 트랜잭션 내에서 에러가 발생하면 전체가 롤백됩니다.
-				return nil, fmt.Errorf("관계 생성 쿼리 실행 실패 (%s->%s): %w", rel.SourceName, rel.TargetName, err)
-			}
 
-			summary, err := result.Consume(ctx)
-			if err != nil {
-				return nil, fmt.Errorf("결과 소비(Consume) 실패 (%s->%s): %w", rel.SourceName, rel.TargetName, err)
-			}
+// InsertRelations 는 관계를 한 번에 저장합니다. 끝 노드가 없어 만들지 못한 관계는 경고만 남기고, 저장 자체가 실패하면 오류를 돌려줍니다.
+func InsertRelations(ctx context.Context, graph db.GraphStore, relations []types.Relation) error {
+	relations = dedupeRelations(relations)
 
-			if summary.Counters().RelationshipsCreated() > 0 {
-				log.Printf("... Neo4j에 관계 '%s-[:%s]->%s' 삽입 완료.", rel.SourceName, rel.Type, rel.TargetName)
-			} else {
-				log.Printf("경고: 관계를 생성하지 못했습니다. 노드를 찾을 수 없음: %s-[:%s]->%s", rel.SourceName, rel.Type, rel.TargetName)
-			}
-		}
+	missing, err := graph.UpsertRelations(ctx, relations)
+	if err != nil {
+		return fmt.Errorf("관계 삽입 트랜잭션이 최종적으로 실패했습니다: %w", err)
+	}
 
-		return nil, nil
-	})
+	missingSet := make(map[string]bool, len(missing))
+	for _, rel := range missing {
+		missingSet[rel.Key()] = true
+	}
+	for _, rel := range relations {
+		if missingSet[rel.Key()] {
+			log.Printf("경고: 관계를 생성하지 못했습니다. 노드를 찾을 수 없음: %s-[:%s]->%s", rel.SourceName, rel.Type, rel.TargetName)
+		} else {
+			log.Printf("... 그래프에 관계 '%s-[:%s]->%s' 병합 완료.", rel.SourceName, rel.Type, rel.TargetName)
+		}
+	}
+	return nil
+}
 
-	if err != nil {
-		log.Fatalf("관계 삽입 트랜잭션이 최종적으로 실패했습니다: %v", err)
+// dedupeRelations 는 출발, 타입, 도착이 같은 관계를 처음 나온 자리에 하나로 합치고 출처를 모읍니다.
+func dedupeRelations(relations []types.Relation) []types.Relation {
+	indexByKey := make(map[string]int, len(relations))
+	var deduped []types.Relation
+	for _, rel := range relations {
+		index, exists := indexByKey[rel.Key()]
+		if !exists {
+			indexByKey[rel.Key()] = len(deduped)
+			deduped = append(deduped, rel)
+			continue
+		}
+		deduped[index].Sources = db.AppendUnique(deduped[index].Sources, rel.Sources...)
 	}
-}
\ No newline at end of file
+	return deduped
+}
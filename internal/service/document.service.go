@@ -0,0 +1,253 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/JCSong-89/trpg-rag-game/internal/db"
+	"github.com/JCSong-89/trpg-rag-game/internal/llm"
+	"github.com/JCSong-89/trpg-rag-game/internal/prompt"
+	"github.com/JCSong-89/trpg-rag-game/pkg/chunker"
+	"github.com/JCSong-89/trpg-rag-game/pkg/ontology"
+	"github.com/JCSong-89/trpg-rag-game/pkg/types"
+	"github.com/JCSong-89/trpg-rag-game/pkg/utils"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// IngestRecord 는 문서 하나를 적재한 기록입니다.
+type IngestRecord struct {
+	Dataset    string    `json:"dataset"`
+	DocumentID string    `json:"documentId"`
+	Source     string    `json:"source"`
+	Hash       string    `json:"hash"`
+	Entities   int       `json:"entities"`
+	Relations  int       `json:"relations"`
+	IngestedAt time.Time `json:"ingestedAt"`
+}
+
+// IngestManifest 는 데이터셋에 어떤 문서가 어떤 내용(Hash)으로 적재되었는지 JSON lines 파일에 추가 전용으로 남깁니다.
+// 같은 문서가 여러 번 기록되면 마지막 기록을 현재 상태로 봅니다. path 가 비어 있으면 메모리에만 둡니다.
+type IngestManifest struct {
+	path string
+
+	mu     sync.Mutex
+	latest map[string]IngestRecord
+}
+
+func OpenIngestManifest(path string) (*IngestManifest, error) {
+	manifest := &IngestManifest{path: path, latest: map[string]IngestRecord{}}
+	if path == "" {
+		return manifest, nil
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return manifest, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("적재 기록 파일 읽기 실패 (%s): %w", path, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record IngestRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			// 중간에 끊긴 줄은 건너뜁니다.
+			continue
+		}
+		manifest.latest[record.DocumentID] = record
+	}
+	return manifest, scanner.Err()
+}
+
+// Unchanged 는 문서가 같은 내용으로 이미 적재되었는지 알려줍니다.
+func (m *IngestManifest) Unchanged(document types.Document) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	record, ok := m.latest[document.ID]
+	return ok && record.Hash == document.Hash
+}
+
+func (m *IngestManifest) Record(record IngestRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.latest[record.DocumentID] = record
+	if m.path == "" {
+		return nil
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(m.path), 0o755); err != nil {
+		return fmt.Errorf("적재 기록 디렉터리 생성 실패 (%s): %w", m.path, err)
+	}
+	file, err := os.OpenFile(m.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("적재 기록 파일 열기 실패 (%s): %w", m.path, err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("적재 기록 쓰기 실패 (%s): %w", m.path, err)
+	}
+	return nil
+}
+
+// IngestDocument 는 문서를 청크로 나눠 청크마다 추출 프롬프트로 엔티티와 관계를 뽑고, 결과를 하나로 합쳐
+// 그래프와 벡터 저장소에 병합합니다. 여러 청크에 나온 같은 ID 의 엔티티는 노드 하나가 됩니다.
+// 문서와 청크 원문도 그래프에 저장하고, 엔티티와 관계에는 자신이 추출된 청크 id 를 출처로 붙입니다.
+// 엔티티 해소가 켜져 있으면 이름만 다른 같은 대상을 저장 전에 하나로 합칩니다.
+// schema 가 있으면 추출 프롬프트에 스키마를 알려주고, 추출 결과를 스키마에 맞춘 뒤 바꾸거나 버린 항목을 로그로 남깁니다.
+// 청크 하나라도 추출에 실패하면 아무것도 저장하지 않고 오류를 돌려줍니다. 저장 중 실패하면 일부만 저장되었을 수 있으므로
+// 호출한 쪽은 오류가 없을 때만 적재 기록을 남겨야 합니다.
+func IngestDocument(ctx context.Context, provider llm.Provider, graph db.GraphStore, vectors db.VectorStore, embedder llm.Embedder, collectionName string, document types.Document, ingestCfg types.IngestConfig, schema *ontology.Ontology) (*types.ParsedData, error) {
+	chunks, err := chunker.Split(document.Text, chunkOptions(document, ingestCfg))
+	if err != nil {
+		return nil, fmt.Errorf("문서 청크 분할 실패 (%s): %w", document.ID, err)
+	}
+
+	results, err := extractChunks(ctx, provider, document, chunks, prompt.OntologyGuideline(schema), ingestCfg.Concurrency)
+	if err != nil {
+		return nil, err
+	}
+	sourceChunks := make([]types.SourceChunk, len(chunks))
+	for i, chunk := range chunks {
+		sourceChunks[i] = sourceChunk(document, chunk)
+		tagSources(&results[i], sourceChunks[i].ID)
+	}
+	parsedData := MergeParsedData(results, ingestCfg.ConflictPolicy)
+	log.Printf("문서 '%s'의 청크 %d개에서 엔티티 %d개, 관계 %d개를 추출했습니다.", document.ID, len(chunks), len(parsedData.Entities), len(parsedData.Relations))
+
+	if schema != nil {
+		var report ontology.Report
+		parsedData, report = schema.Normalize(parsedData)
+		log.Printf("문서 '%s' 스키마 검사: %s", document.ID, report)
+		for _, issue := range report.Issues {
+			log.Printf("... %s", issue)
+		}
+	}
+
+	if ingestCfg.Resolution.Enabled {
+		parsedData, err = ResolveEntities(ctx, vectors, embedder, collectionName, parsedData, ingestCfg.Resolution, ingestCfg.ConflictPolicy)
+		if err != nil {
+			return nil, fmt.Errorf("엔티티 해소 실패 (%s): %w", document.ID, err)
+		}
+	}
+
+	if err := graph.UpsertDocument(ctx, document, sourceChunks); err != nil {
+		return nil, fmt.Errorf("문서 출처 저장 실패 (%s): %w", document.ID, err)
+	}
+
+	if err := ProcessAndStoreEntities(ctx, graph, vectors, embedder, collectionName, parsedData.Entities, ingestCfg); err != nil {
+		return nil, fmt.Errorf("엔티티 저장 실패 (%s): %w", document.ID, err)
+	}
+	if err := InsertRelations(ctx, graph, parsedData.Relations); err != nil {
+		return nil, fmt.Errorf("관계 저장 실패 (%s): %w", document.ID, err)
+	}
+	return &parsedData, nil
+}
+
+// sourceChunk 는 청크를 출처로 저장할 형태로 바꿉니다. id 는 문서 안에서의 순번으로 정하므로 다시 적재해도 같습니다.
+func sourceChunk(document types.Document, chunk chunker.Chunk) types.SourceChunk {
+	return types.SourceChunk{
+		ID:         fmt.Sprintf("%s#chunk-%d", document.ID, chunk.Index),
+		DocumentID: document.ID,
+		Source:     document.Source,
+		Index:      chunk.Index,
+		Heading:    chunk.Heading,
+		Text:       chunk.Text,
+		StartLine:  chunk.StartLine,
+		EndLine:    chunk.EndLine,
+		Page:       chunk.Page,
+	}
+}
+
+// tagSources 는 청크 하나의 추출 결과에 그 청크 id 를 출처로 붙입니다. 모델이 채운 값은 버립니다.
+func tagSources(result *types.ParsedData, chunkID string) {
+	for i := range result.Entities {
+		result.Entities[i].Sources = []string{chunkID}
+	}
+	for i := range result.Relations {
+		result.Relations[i].Sources = []string{chunkID}
+	}
+}
+
+// chunkOptions 는 설정에 청크 전략이 없으면 Markdown 문서는 제목 기준, 그 밖의 문서는 문단 기준으로 나눕니다.
+func chunkOptions(document types.Document, ingestCfg types.IngestConfig) chunker.Options {
+	strategy := chunker.Strategy(ingestCfg.ChunkStrategy)
+	if strategy == "" {
+		strategy = chunker.StrategyParagraph
+		if document.Format == utils.DocumentFormatMarkdown {
+			strategy = chunker.StrategyMarkdown
+		}
+	}
+	return chunker.Options{Strategy: strategy, MaxTokens: ingestCfg.ChunkTokens, OverlapTokens: ingestCfg.ChunkOverlap}
+}
+
+// extractChunks 는 최대 concurrency 개의 청크를 동시에 추출합니다. 결과는 청크 순서를 따릅니다.
+// guideline 은 추출 프롬프트에 넣을 스키마 안내입니다.
+func extractChunks(ctx context.Context, provider llm.Provider, document types.Document, chunks []chunker.Chunk, guideline string, concurrency int) ([]types.ParsedData, error) {
+	if concurrency <= 0 {
+		concurrency = defaultIngestConcurrency
+	}
+
+	results := make([]types.ParsedData, len(chunks))
+	errs := make([]error, len(chunks))
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, concurrency)
+
+	for i, chunk := range chunks {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(i int, chunk chunker.Chunk) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			extractionPrompt := fmt.Sprintf(prompt.ExtractionPromptTemplate, guideline, chunkPromptText(chunk))
+			if _, err := llm.GenerateJSON(ctx, provider, extractionPrompt, &results[i], llm.WithStage(llm.StageExtraction)); err != nil {
+				errs[i] = fmt.Errorf("%s 엔티티 추출 실패 (%s, 청크 %d/%d): %w", provider.Name(), document.ID, i+1, len(chunks), err)
+				return
+			}
+			log.Printf("... 문서 '%s' 청크 %d/%d: 엔티티 %d개, 관계 %d개", document.ID, i+1, len(chunks), len(results[i].Entities), len(results[i].Relations))
+		}(i, chunk)
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// chunkPromptText 는 구역 중간에서 시작하는 청크 앞에 제목 경로를 붙여, 모델이 어떤 장의 내용인지 알 수 있게 합니다.
+func chunkPromptText(chunk chunker.Chunk) string {
+	if chunk.Heading == "" || strings.HasPrefix(chunk.Text, "#") {
+		return chunk.Text
+	}
+	return fmt.Sprintf("[%s]\n%s", chunk.Heading, chunk.Text)
+}
+
+// MergeParsedData 는 청크별 추출 결과를 하나로 합칩니다. 같은 ID 의 엔티티는 처음 나온 자리에 하나로 합치고
+// 속성은 policy 에 따라 병합하며, 같은 관계는 한 번만 남깁니다. 합쳐진 엔티티와 관계의 출처는 모두 모읍니다.
+func MergeParsedData(results []types.ParsedData, policy types.ConflictPolicy) types.ParsedData {
+	var merged types.ParsedData
+	for _, result := range results {
+		merged.Entities = append(merged.Entities, result.Entities...)
+		merged.Relations = append(merged.Relations, result.Relations...)
+	}
+	merged.Entities = dedupeEntities(merged.Entities, policy)
+	merged.Relations = dedupeRelations(merged.Relations)
+	return merged
+}
@@ -0,0 +1,391 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/JCSong-89/trpg-rag-game/internal/db"
+	"github.com/JCSong-89/trpg-rag-game/internal/llm"
+	"github.com/JCSong-89/trpg-rag-game/pkg/types"
+	"github.com/JCSong-89/trpg-rag-game/pkg/utils"
+	"log"
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// resolutionCandidate 는 해소할 때 비교하는 엔티티 하나입니다. keys 는 이름 표기를 정규화한 값이고,
+// tokenSets 는 이름과 별칭마다 공백이나 문장 부호로 구분된 단어로 나눈 집합입니다.
+type resolutionCandidate struct {
+	entity    types.Entity
+	keys      []string
+	tokenSets [][]string
+}
+
+func newResolutionCandidate(entity types.Entity) resolutionCandidate {
+	candidate := resolutionCandidate{entity: entity}
+	for _, name := range append([]string{entity.ID, entity.Name}, entity.Aliases...) {
+		for _, tokens := range nameTokenVariants(name) {
+			candidate.keys = db.AppendUnique(candidate.keys, nameKeys(tokens)...)
+			if name != entity.ID {
+				candidate.tokenSets = append(candidate.tokenSets, tokens)
+			}
+		}
+	}
+	return candidate
+}
+
+// storedMatch 는 이미 저장된 엔티티 중 해소 대상과 같다고 본 엔티티입니다.
+type storedMatch struct {
+	entity types.Entity
+	score  float64
+}
+
+// ResolveEntities 는 추출 결과에서 같은 대상을 가리키는 엔티티를 묶어 대표 엔티티 하나로 합치고,
+// 관계의 양 끝을 대표 엔티티 ID 로 바꿉니다. 묶인 엔티티의 다른 이름은 대표 엔티티의 Aliases 에 남습니다.
+// 이미 저장된 엔티티와 같다고 보이면 저장된 엔티티의 ID 와 이름을 대표로 씁니다.
+// 임베딩을 계산한 엔티티는 Embedding 이 채워진 채로 돌려주므로 저장할 때 다시 계산하지 않습니다.
+func ResolveEntities(ctx context.Context, vectors db.VectorStore, embedder llm.Embedder, collectionName string, data types.ParsedData, cfg types.ResolutionConfig, policy types.ConflictPolicy) (types.ParsedData, error) {
+	entities := dedupeEntities(data.Entities, policy)
+	if len(entities) == 0 {
+		return data, nil
+	}
+
+	texts := make([]string, len(entities))
+	for i, entity := range entities {
+		texts[i] = buildEmbeddingText(entity)
+	}
+	embeddings, err := embedder.Embed(ctx, texts)
+	if err != nil {
+		return data, fmt.Errorf("엔티티 해소용 임베딩 생성 실패: %w", err)
+	}
+	if len(embeddings) != len(entities) {
+		return data, fmt.Errorf("임베딩 개수가 입력과 다릅니다 (입력: %d, 응답: %d)", len(entities), len(embeddings))
+	}
+
+	candidates := make([]resolutionCandidate, len(entities))
+	for i := range entities {
+		entities[i].Embedding = embeddings[i]
+		candidates[i] = newResolutionCandidate(entities[i])
+	}
+
+	clusters := newUnionFind(len(entities))
+	for i := range candidates {
+		for j := i + 1; j < len(candidates); j++ {
+			similarity := cosineSimilarity(entities[i].Embedding, entities[j].Embedding)
+			if shouldMerge(candidates[i], candidates[j], similarity, cfg) {
+				clusters.union(i, j)
+			}
+		}
+	}
+
+	matches, err := findStoredMatches(ctx, vectors, collectionName, candidates, cfg)
+	if err != nil {
+		return data, err
+	}
+
+	members := map[int][]int{}
+	var roots []int
+	for i := range entities {
+		root := clusters.find(i)
+		if _, exists := members[root]; !exists {
+			roots = append(roots, root)
+		}
+		members[root] = append(members[root], i)
+	}
+
+	idMap := map[string]string{}
+	keyMap := map[string]string{}
+	resolved := make([]types.Entity, 0, len(roots))
+	for _, root := range roots {
+		var match *storedMatch
+		for _, i := range members[root] {
+			if m, ok := matches[i]; ok && (match == nil || m.score > match.score) {
+				match = &m
+			}
+		}
+
+		entity := mergeCluster(entities, members[root], match)
+		for _, i := range members[root] {
+			idMap[entities[i].ID] = entity.ID
+			for _, key := range candidates[i].keys {
+				keyMap[key] = entity.ID
+			}
+		}
+		resolved = append(resolved, entity)
+	}
+
+	relations := make([]types.Relation, 0, len(data.Relations))
+	for _, relation := range data.Relations {
+		selfLoop := relation.SourceName == relation.TargetName
+		relation.SourceName = resolveEndpoint(relation.SourceName, idMap, keyMap)
+		relation.TargetName = resolveEndpoint(relation.TargetName, idMap, keyMap)
+		if relation.SourceName == relation.TargetName && !selfLoop {
+			// 합쳐진 두 엔티티 사이의 관계는 자기 자신을 가리키게 되므로 버립니다.
+			continue
+		}
+		relations = append(relations, relation)
+	}
+
+	result := types.ParsedData{Entities: dedupeEntities(resolved, types.ConflictKeep), Relations: dedupeRelations(relations)}
+	log.Printf("엔티티 해소: %d개 → %d개 (저장된 엔티티와 연결 %d개), 관계 %d개 → %d개", len(entities), len(result.Entities), len(matches), len(data.Relations), len(result.Relations))
+	return result, nil
+}
+
+// findStoredMatches 는 엔티티마다 벡터 저장소에서 CandidateTopK 개 후보를 찾아, 같다고 볼 수 있는 후보 중
+// 가장 유사한 것을 엔티티 순번별로 돌려줍니다.
+func findStoredMatches(ctx context.Context, vectors db.VectorStore, collectionName string, candidates []resolutionCandidate, cfg types.ResolutionConfig) (map[int]storedMatch, error) {
+	matches := map[int]storedMatch{}
+	if cfg.CandidateTopK <= 0 {
+		return matches, nil
+	}
+	for i, candidate := range candidates {
+		found, err := vectors.Search(ctx, collectionName, candidate.entity.Embedding, cfg.CandidateTopK, nil)
+		if err != nil {
+			return nil, fmt.Errorf("엔티티 해소 후보 검색 실패 (%s): %w", candidate.entity.ID, err)
+		}
+		for _, point := range found {
+			stored := types.Entity{
+				ID:      payloadString(point.Payload["entityId"]),
+				Name:    payloadString(point.Payload["name"]),
+				Label:   payloadString(point.Payload["label"]),
+				Aliases: payloadStrings(point.Payload["aliases"]),
+			}
+			if stored.ID == "" {
+				continue
+			}
+			score := float64(point.Score)
+			if best, ok := matches[i]; ok && best.score >= score {
+				continue
+			}
+			if shouldMerge(candidate, newResolutionCandidate(stored), score, cfg) {
+				matches[i] = storedMatch{entity: stored, score: score}
+			}
+		}
+	}
+	return matches, nil
+}
+
+// shouldMerge 는 두 엔티티를 같은 대상으로 볼지 정합니다. ID 가 같으면 항상 합치고, 그 밖에는 라벨이 호환될 때
+// 이름 표기가 같거나, 임베딩이 충분히 비슷하거나, 한쪽 이름이 다른 쪽 이름의 일부이면서 어느 정도 비슷하면 합칩니다.
+func shouldMerge(a resolutionCandidate, b resolutionCandidate, similarity float64, cfg types.ResolutionConfig) bool {
+	if a.entity.ID == b.entity.ID {
+		return true
+	}
+	if !labelsCompatible(a.entity.Label, b.entity.Label) {
+		return false
+	}
+	for _, key := range a.keys {
+		if slices.Contains(b.keys, key) {
+			return true
+		}
+	}
+	if similarity >= cfg.Similarity {
+		return true
+	}
+	if similarity < cfg.AliasSimilarity {
+		return false
+	}
+	for _, tokensA := range a.tokenSets {
+		for _, tokensB := range b.tokenSets {
+			if isTokenSubset(tokensA, tokensB) || isTokenSubset(tokensB, tokensA) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// mergeCluster 는 한 묶음의 엔티티를 하나로 합칩니다. 대표는 저장된 엔티티가 있으면 그 엔티티이고,
+// 없으면 출처가 가장 많은 엔티티, 출처 수가 같으면 이름이 가장 긴 엔티티입니다. 속성은 대표의 값을 우선합니다.
+func mergeCluster(entities []types.Entity, members []int, match *storedMatch) types.Entity {
+	canonical := members[0]
+	for _, i := range members[1:] {
+		current, candidate := entities[canonical], entities[i]
+		if len(candidate.Sources) > len(current.Sources) ||
+			len(candidate.Sources) == len(current.Sources) && utf8.RuneCountInString(candidate.Name) > utf8.RuneCountInString(current.Name) {
+			canonical = i
+		}
+	}
+
+	merged := entities[canonical]
+	merged.Aliases = slices.Clone(merged.Aliases)
+	for _, i := range members {
+		if i == canonical {
+			continue
+		}
+		other := entities[i]
+		merged.Properties = db.MergeProperties(merged.Properties, other.Properties, types.ConflictKeep)
+		merged.Sources = db.AppendUnique(slices.Clone(merged.Sources), other.Sources...)
+		merged.Aliases = db.AppendUnique(merged.Aliases, other.Name)
+		merged.Aliases = db.AppendUnique(merged.Aliases, other.Aliases...)
+	}
+
+	if match != nil && (match.entity.ID != merged.ID || match.entity.Name != merged.Name) {
+		merged.Aliases = db.AppendUnique(merged.Aliases, merged.Name)
+		merged.ID, merged.Name = match.entity.ID, match.entity.Name
+		if match.entity.Label != "" {
+			merged.Label = match.entity.Label
+		}
+		merged.Embedding = nil
+	}
+	if len(members) > 1 {
+		merged.Embedding = nil
+	}
+	merged.Aliases = slices.DeleteFunc(merged.Aliases, func(alias string) bool { return alias == "" || alias == merged.Name })
+	return merged
+}
+
+// resolveEndpoint 는 관계 끝의 값을 대표 엔티티 ID 로 바꿉니다. ID 로 먼저 찾고, 모델이 이름을 썼을 때를 위해 이름 표기로도 찾습니다.
+func resolveEndpoint(value string, idMap map[string]string, keyMap map[string]string) string {
+	if id, ok := idMap[value]; ok {
+		return id
+	}
+	for _, tokens := range nameTokenVariants(value) {
+		for _, key := range nameKeys(tokens) {
+			if id, ok := keyMap[key]; ok {
+				return id
+			}
+		}
+	}
+	return value
+}
+
+// labelsCompatible 은 대소문자와 구분자를 무시하고 라벨이 같거나, 한쪽 라벨이 비어 있으면 true 입니다.
+func labelsCompatible(a string, b string) bool {
+	a, b = strings.Join(nameWords(a), ""), strings.Join(nameWords(b), "")
+	return a == "" || b == "" || a == b
+}
+
+// nameWords 는 이름을 소문자 단어로 나눕니다. 글자와 숫자가 아닌 문자는 구분자로 보고, 한글은 로마자로 옮기되
+// 음절로 나누지 않고 단어 그대로 둡니다. 예: "Son Heung-min" → [son heung min], "손흥민" → [sonheungmin]
+func nameWords(name string) []string {
+	var words []string
+	var word strings.Builder
+	for _, r := range name {
+		switch {
+		case utils.IsHangulSyllable(r):
+			word.WriteString(utils.RomanizeHangulSyllable(r))
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			word.WriteRune(unicode.ToLower(r))
+		case word.Len() > 0:
+			words = append(words, word.String())
+			word.Reset()
+		}
+	}
+	if word.Len() > 0 {
+		words = append(words, word.String())
+	}
+	return words
+}
+
+// nameTokenVariants 는 nameWords 에 더해, 2~4 음절 한글 이름이 굳어진 성씨 표기로 시작하는 형태도 돌려줍니다.
+// 예: "김민재" → [gimminjae], [kimminjae]
+func nameTokenVariants(name string) [][]string {
+	words := nameWords(name)
+	if len(words) == 0 {
+		return nil
+	}
+	variants := [][]string{words}
+
+	runes := []rune(strings.TrimSpace(name))
+	if len(runes) < 2 || len(runes) > 4 || !utils.IsHangulSyllable(runes[0]) {
+		return variants
+	}
+	for _, r := range runes {
+		if !utils.IsHangulSyllable(r) {
+			return variants
+		}
+	}
+	if surname, ok := utils.KoreanSurnameRomanization(runes[0]); ok && surname != utils.RomanizeHangulSyllable(runes[0]) {
+		variants = append(variants, []string{surname + utils.RomanizeHangul(string(runes[1:]))})
+	}
+	return variants
+}
+
+// nameKeys 는 단어를 그대로 이은 값과, 어순이 달라도 같도록 정렬해 이은 값을 돌려줍니다. 정렬은 단어 단위라서
+// 한글 단어 안의 음절 순서는 바뀌지 않습니다 ("소주" 와 "주소" 는 다른 키입니다).
+func nameKeys(words []string) []string {
+	if len(words) == 0 {
+		return nil
+	}
+	sorted := slices.Clone(words)
+	slices.Sort(sorted)
+	return db.AppendUnique([]string{strings.Join(words, "")}, strings.Join(sorted, ""))
+}
+
+// isTokenSubset 은 part 의 단어가 모두 whole 에 있고 whole 이 더 길 때 true 입니다.
+func isTokenSubset(part []string, whole []string) bool {
+	if len(part) == 0 || len(part) >= len(whole) {
+		return false
+	}
+	for _, token := range part {
+		if !slices.Contains(whole, token) {
+			return false
+		}
+	}
+	return true
+}
+
+func cosineSimilarity(a []float32, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+func payloadString(value any) string {
+	s, _ := value.(string)
+	return s
+}
+
+func payloadStrings(value any) []string {
+	items, _ := value.([]any)
+	var values []string
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			values = append(values, s)
+		}
+	}
+	return values
+}
+
+// unionFind 는 합칠 엔티티 묶음을 관리합니다.
+type unionFind struct {
+	parent []int
+}
+
+func newUnionFind(size int) *unionFind {
+	parent := make([]int, size)
+	for i := range parent {
+		parent[i] = i
+	}
+	return &unionFind{parent: parent}
+}
+
+func (u *unionFind) find(i int) int {
+	for u.parent[i] != i {
+		u.parent[i] = u.parent[u.parent[i]]
+		i = u.parent[i]
+	}
+	return i
+}
+
+// union 은 더 앞선 순번을 묶음의 대표로 둡니다. 그래서 묶음 순서가 추출 순서를 따릅니다.
+func (u *unionFind) union(a int, b int) {
+	rootA, rootB := u.find(a), u.find(b)
+	if rootA > rootB {
+		rootA, rootB = rootB, rootA
+	}
+	u.parent[rootB] = rootA
+}
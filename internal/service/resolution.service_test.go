@@ -0,0 +1,82 @@
+package service
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/JCSong-89/trpg-rag-game/pkg/types"
+)
+
+func TestShouldMergeByNameKey(t *testing.T) {
+	cfg := types.ResolutionConfig{Similarity: 0.92, AliasSimilarity: 0.75}
+	tests := []struct {
+		name string
+		a    types.Entity
+		b    types.Entity
+		want bool
+	}{
+		{
+			name: "한글 이름과 로마자 ID",
+			a:    types.Entity{ID: "son_heung_min", Name: "Son Heung-min", Label: "Person"},
+			b:    types.Entity{ID: "손흥민", Name: "손흥민", Label: "Person"},
+			want: true,
+		},
+		{
+			name: "영문 어순만 다름",
+			a:    types.Entity{ID: "son_heung_min", Name: "Son Heung-min", Label: "Person"},
+			b:    types.Entity{ID: "heung_min_son", Name: "Heung-min Son", Label: "Person"},
+			want: true,
+		},
+		{
+			name: "굳어진 성씨 표기",
+			a:    types.Entity{ID: "kim_min_jae", Name: "Kim Min-jae", Label: "Person"},
+			b:    types.Entity{ID: "김민재", Name: "김민재", Label: "Person"},
+			want: true,
+		},
+		{
+			name: "음절 순서가 뒤집힌 한글 단어 (소주/주소)",
+			a:    types.Entity{ID: "소주", Name: "소주", Label: "Item"},
+			b:    types.Entity{ID: "주소", Name: "주소", Label: "Item"},
+			want: false,
+		},
+		{
+			name: "음절 순서가 뒤집힌 한글 단어 (국민/민국)",
+			a:    types.Entity{ID: "국민", Name: "국민", Label: "Concept"},
+			b:    types.Entity{ID: "민국", Name: "민국", Label: "Concept"},
+			want: false,
+		},
+		{
+			name: "이름이 같아도 라벨이 다름",
+			a:    types.Entity{ID: "mercury_planet", Name: "Mercury", Label: "Planet"},
+			b:    types.Entity{ID: "mercury", Name: "Mercury", Label: "Element"},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := shouldMerge(newResolutionCandidate(tt.a), newResolutionCandidate(tt.b), 0, cfg)
+			if got != tt.want {
+				t.Errorf("shouldMerge(%s, %s) = %v, want %v", tt.a.ID, tt.b.ID, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNameKeysSortOnlyWords(t *testing.T) {
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{name: "Son Heung-min", want: []string{"sonheungmin", "heungminson"}},
+		{name: "소주", want: []string{"soju"}},
+		{name: "주소", want: []string{"juso"}},
+	}
+
+	for _, tt := range tests {
+		got := nameKeys(nameWords(tt.name))
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("nameKeys(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"github.com/JCSong-89/trpg-rag-game/internal/db"
+	"github.com/JCSong-89/trpg-rag-game/pkg/types"
+	"log"
+)
+
+// CollectSourceChunks 는 서브그래프의 근거가 된 청크를 최대 limit 개 모읍니다.
+// 관계에 붙은 출처를 먼저 쓰고, 모자라면 엔티티가 언급된 청크를 엔티티마다 하나씩 더합니다.
+func CollectSourceChunks(ctx context.Context, graph db.GraphStore, subgraph *types.Subgraph, limit int) ([]types.SourceChunk, error) {
+	if subgraph == nil || limit <= 0 {
+		return nil, nil
+	}
+
+	var chunkIDs []string
+	for _, relation := range subgraph.Relations {
+		chunkIDs = db.AppendUnique(chunkIDs, relation.Sources...)
+	}
+	chunks, err := graph.Chunks(ctx, chunkIDs[:min(limit, len(chunkIDs))])
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	for _, chunk := range chunks {
+		seen[chunk.ID] = true
+	}
+	for _, entity := range subgraph.Entities {
+		if len(chunks) >= limit {
+			break
+		}
+		mentions, err := graph.MentionChunks(ctx, entity.Name, 1)
+		if err != nil {
+			return nil, err
+		}
+		for _, chunk := range mentions {
+			if !seen[chunk.ID] {
+				seen[chunk.ID] = true
+				chunks = append(chunks, chunk)
+			}
+		}
+	}
+
+	log.Printf("출처 청크 %d개를 모았습니다.", len(chunks))
+	return chunks, nil
+}
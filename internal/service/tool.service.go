@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/JCSong-89/trpg-rag-game/internal/db"
+	"github.com/JCSong-89/trpg-rag-game/internal/llm"
+	"github.com/JCSong-89/trpg-rag-game/pkg/types"
+	"github.com/JCSong-89/trpg-rag-game/pkg/utils"
+)
+
+const (
+	defaultToolSearchTopK  = 5
+	maxToolSearchTopK      = 20
+	defaultToolMaxHops     = 2
+	maxToolMaxHops         = 4
+	defaultToolSourceLimit = 3
+	maxToolSourceLimit     = 10
+)
+
+type lookupEntityArgs struct {
+	Name string `json:"name"`
+}
+
+type searchEntitiesArgs struct {
+	Query string `json:"query"`
+	TopK  int    `json:"top_k,omitempty"`
+}
+
+type expandMultiHopArgs struct {
+	Name    string `json:"name"`
+	MaxHops int    `json:"max_hops,omitempty"`
+}
+
+type quoteSourcesArgs struct {
+	Name     string   `json:"name,omitempty"`
+	ChunkIDs []string `json:"chunk_ids,omitempty"`
+	Limit    int      `json:"limit,omitempty"`
+}
+
+type rollDiceArgs struct {
+	Notation string `json:"notation"`
+}
+
+// NewGameMasterTools 는 게임 마스터 에이전트가 호출할 그래프 조회, 벡터 검색, 멀티홉 확장, 출처 인용, 주사위 도구를 등록합니다.
+// 멀티홉 깊이와 검색 개수는 모델이 과도한 조회를 요청하지 못하도록 상한을 둡니다.
+func NewGameMasterTools(graph db.GraphStore, vectors db.VectorStore, embedder llm.Embedder, collectionName string) *llm.ToolRegistry {
+	registry := llm.NewToolRegistry()
+
+	llm.RegisterTool(registry, "lookup_entity",
+		"Look up an entity in the knowledge graph by its exact name and return it with its direct relationships.",
+		func(ctx context.Context, args lookupEntityArgs) (any, error) {
+			subgraph, err := GetOneHopSubgraph(ctx, graph, args.Name)
+			if err != nil {
+				return nil, err
+			}
+			if len(subgraph.Entities) == 0 {
+				return fmt.Sprintf("'%s' 이름의 엔티티를 찾지 못했습니다. search_entities 로 이름을 먼저 찾아보세요.", args.Name), nil
+			}
+			return utils.SubgraphToString(subgraph), nil
+		})
+
+	llm.RegisterTool(registry, "search_entities",
+		"Find entity names in the knowledge graph that are semantically similar to a free-text query. Use this when you do not know the exact entity name.",
+		func(ctx context.Context, args searchEntitiesArgs) (any, error) {
+			topK := args.TopK
+			if topK <= 0 {
+				topK = defaultToolSearchTopK
+			}
+			topK = min(topK, maxToolSearchTopK)
+
+			names, err := FindTopKSimilarEntities(ctx, vectors, embedder, collectionName, args.Query, topK)
+			if err != nil {
+				return nil, err
+			}
+			return map[string][]string{"entities": names}, nil
+		})
+
+	llm.RegisterTool(registry, "expand_multi_hop",
+		fmt.Sprintf("Expand the knowledge graph around an entity up to max_hops relationships away (default %d, at most %d) to follow indirect connections such as causes and motivations.", defaultToolMaxHops, maxToolMaxHops),
+		func(ctx context.Context, args expandMultiHopArgs) (any, error) {
+			maxHops := args.MaxHops
+			if maxHops <= 0 {
+				maxHops = defaultToolMaxHops
+			}
+			maxHops = min(maxHops, maxToolMaxHops)
+
+			subgraph, err := GetMultiHopSubgraph(ctx, graph, args.Name, maxHops)
+			if err != nil {
+				return nil, err
+			}
+			if len(subgraph.Entities) == 0 {
+				return fmt.Sprintf("'%s' 주변에서 연결된 엔티티를 찾지 못했습니다.", args.Name), nil
+			}
+			return utils.SubgraphToString(subgraph), nil
+		})
+
+	llm.RegisterTool(registry, "quote_sources",
+		fmt.Sprintf("Return the original source text (rulebook or session log excerpts) with citations, either for given source ids such as those listed next to relationships, or for the chunks that mention an entity by exact name (default %d, at most %d).", defaultToolSourceLimit, maxToolSourceLimit),
+		func(ctx context.Context, args quoteSourcesArgs) (any, error) {
+			limit := args.Limit
+			if limit <= 0 {
+				limit = defaultToolSourceLimit
+			}
+			limit = min(limit, maxToolSourceLimit)
+
+			var chunks []types.SourceChunk
+			var err error
+			switch {
+			case len(args.ChunkIDs) > 0:
+				chunks, err = graph.Chunks(ctx, args.ChunkIDs[:min(limit, len(args.ChunkIDs))])
+			case args.Name != "":
+				chunks, err = graph.MentionChunks(ctx, args.Name, limit)
+			default:
+				return "name 또는 chunk_ids 중 하나를 지정하세요.", nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			return utils.SourceChunksToString(chunks, 0), nil
+		})
+
+	llm.RegisterTool(registry, "roll_dice",
+		"Roll dice using standard tabletop notation such as d20, 2d6+3 or 4d8-1 and return each die and the total.",
+		func(ctx context.Context, args rollDiceArgs) (any, error) {
+			return utils.RollDice(args.Notation)
+		})
+
+	return registry
+}
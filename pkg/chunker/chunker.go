@@ -0,0 +1,306 @@
+package chunker
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Strategy 는 문서를 나누는 기준입니다.
+type Strategy string
+
+const (
+	// StrategyFixed 는 단어 경계를 지키면서 토큰 수만 보고 자릅니다.
+	StrategyFixed Strategy = "fixed"
+	// StrategySentence 는 문장 단위로 모아 자릅니다.
+	StrategySentence Strategy = "sentence"
+	// StrategyParagraph 는 빈 줄로 구분된 문단 단위로 모아 자릅니다.
+	StrategyParagraph Strategy = "paragraph"
+	// StrategyMarkdown 은 Markdown 제목(#)으로 구역을 나누고, 청크가 구역 경계를 넘지 않게 자릅니다.
+	StrategyMarkdown Strategy = "markdown"
+)
+
+const (
+	DefaultMaxTokens     = 1000
+	DefaultOverlapTokens = 100
+
+	// 토큰 수는 모델마다 다르므로 룬 3개를 1토큰으로 어림합니다.
+	runesPerToken = 3
+)
+
+// Options 의 OverlapTokens 는 앞 청크의 끝부분을 다음 청크 앞에 다시 넣는 양입니다.
+// 겹치는 부분도 단어/문장/문단 경계에서 잘리므로 실제 겹침은 이보다 적을 수 있습니다.
+type Options struct {
+	Strategy      Strategy
+	MaxTokens     int
+	OverlapTokens int
+}
+
+// Chunk 의 Heading 은 Markdown 전략에서 청크가 속한 구역의 제목 경로("장 > 절")이며, 그 밖에는 비어 있습니다.
+// StartLine, EndLine 은 원문 기준 줄 번호(1부터)이고, Page 는 원문에 쪽 나눔 문자(\f)가 있을 때만 1 이상입니다.
+type Chunk struct {
+	Index     int
+	Text      string
+	Heading   string
+	Tokens    int
+	StartLine int
+	EndLine   int
+	Page      int
+}
+
+// segment 는 청크를 이루는 원문 조각입니다. 뒤따르는 공백까지 포함해 이어 붙이면 원문이 됩니다.
+type segment struct {
+	text    string
+	heading string
+	section int
+	tokens  int
+}
+
+// ParseStrategy 는 설정 값을 Strategy 로 바꿉니다. 빈 문자열은 그대로 돌려주며, 문서 형식에 따라 고르라는 뜻입니다.
+func ParseStrategy(raw string) (Strategy, error) {
+	switch strategy := Strategy(strings.ToLower(strings.TrimSpace(raw))); strategy {
+	case "", StrategyFixed, StrategySentence, StrategyParagraph, StrategyMarkdown:
+		return strategy, nil
+	default:
+		return "", fmt.Errorf("알 수 없는 청크 전략입니다: %s", raw)
+	}
+}
+
+// EstimateTokens 는 text 의 토큰 수를 어림합니다.
+func EstimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + runesPerToken - 1) / runesPerToken
+}
+
+// Split 은 text 를 MaxTokens 이하의 청크로 나눕니다. 기준 단위 하나가 MaxTokens 를 넘으면
+// 문단 → 문장 → 단어 → 글자 순으로 더 잘게 나눕니다. 본문이 비어 있으면 청크도 없습니다.
+func Split(text string, opts Options) ([]Chunk, error) {
+	maxTokens := opts.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = DefaultMaxTokens
+	}
+	overlap := opts.OverlapTokens
+	if overlap < 0 {
+		overlap = 0
+	}
+	if overlap >= maxTokens {
+		return nil, fmt.Errorf("청크 겹침(%d)은 청크 크기(%d)보다 작아야 합니다", overlap, maxTokens)
+	}
+
+	var segments []segment
+	switch opts.Strategy {
+	case StrategyFixed:
+		segments = refine(newSegments(splitWords(text), "", 0), maxTokens, levelRunes)
+	case StrategySentence:
+		segments = refine(newSegments(splitSentences(text), "", 0), maxTokens, levelWords)
+	case StrategyParagraph, "":
+		segments = refine(newSegments(splitParagraphs(text), "", 0), maxTokens, levelSentences)
+	case StrategyMarkdown:
+		for i, section := range splitMarkdownSections(text) {
+			segments = append(segments, refine(newSegments([]string{section.text}, section.heading, i), maxTokens, levelParagraphs)...)
+		}
+	default:
+		return nil, fmt.Errorf("알 수 없는 청크 전략입니다: %s", opts.Strategy)
+	}
+	chunks := pack(segments, maxTokens, overlap)
+	locate(text, chunks)
+	return chunks, nil
+}
+
+// locate 는 청크마다 원문에서의 줄 번호와 쪽 번호를 채웁니다. 청크는 원문 순서대로 나오므로
+// 앞 청크의 시작 위치부터 찾습니다.
+func locate(text string, chunks []Chunk) {
+	paged := strings.Contains(text, "\f")
+	offset, line, page := 0, 1, 1
+	for i := range chunks {
+		start := strings.Index(text[offset:], chunks[i].Text)
+		if start < 0 {
+			continue
+		}
+		start += offset
+		line += strings.Count(text[offset:start], "\n")
+		page += strings.Count(text[offset:start], "\f")
+		offset = start
+
+		chunks[i].StartLine = line
+		chunks[i].EndLine = line + strings.Count(chunks[i].Text, "\n")
+		if paged {
+			chunks[i].Page = page
+		}
+	}
+}
+
+// 단위가 너무 클 때 다음으로 시도할 분할 단계입니다.
+const (
+	levelParagraphs = iota
+	levelSentences
+	levelWords
+	levelRunes
+)
+
+func newSegments(pieces []string, heading string, section int) []segment {
+	segments := make([]segment, 0, len(pieces))
+	for _, piece := range pieces {
+		if piece == "" {
+			continue
+		}
+		segments = append(segments, segment{text: piece, heading: heading, section: section, tokens: EstimateTokens(piece)})
+	}
+	return segments
+}
+
+// refine 은 maxTokens 를 넘는 조각을 level 부터 차례로 더 잘게 나눕니다.
+func refine(segments []segment, maxTokens int, level int) []segment {
+	var refined []segment
+	for _, seg := range segments {
+		if seg.tokens <= maxTokens {
+			refined = append(refined, seg)
+			continue
+		}
+
+		var pieces []string
+		switch level {
+		case levelParagraphs:
+			pieces = splitParagraphs(seg.text)
+		case levelSentences:
+			pieces = splitSentences(seg.text)
+		case levelWords:
+			pieces = splitWords(seg.text)
+		default:
+			pieces = splitRunes(seg.text, maxTokens*runesPerToken)
+		}
+		if len(pieces) <= 1 && level < levelRunes {
+			refined = append(refined, refine([]segment{seg}, maxTokens, level+1)...)
+			continue
+		}
+		refined = append(refined, refine(newSegments(pieces, seg.heading, seg.section), maxTokens, min(level+1, levelRunes))...)
+	}
+	return refined
+}
+
+// pack 은 조각을 앞에서부터 maxTokens 까지 채워 청크로 묶습니다. 새 청크는 앞 청크의 마지막 조각들을
+// overlap 토큰 안에서 다시 넣고 시작하지만, Markdown 구역이 바뀌는 곳에서는 겹치지 않습니다.
+func pack(segments []segment, maxTokens int, overlap int) []Chunk {
+	var chunks []Chunk
+	var current []segment
+	currentTokens := 0
+
+	flush := func() {
+		var builder strings.Builder
+		for _, seg := range current {
+			builder.WriteString(seg.text)
+		}
+		text := strings.TrimSpace(builder.String())
+		if text == "" {
+			return
+		}
+		chunks = append(chunks, Chunk{Index: len(chunks), Text: text, Heading: current[0].heading, Tokens: EstimateTokens(text)})
+	}
+
+	for _, seg := range segments {
+		if len(current) > 0 && (currentTokens+seg.tokens > maxTokens || current[len(current)-1].section != seg.section) {
+			flush()
+
+			tail := overlapTail(current, overlap, seg.section)
+			current, currentTokens = tail, 0
+			for _, t := range tail {
+				currentTokens += t.tokens
+			}
+			if currentTokens+seg.tokens > maxTokens {
+				current, currentTokens = nil, 0
+			}
+		}
+		current = append(current, seg)
+		currentTokens += seg.tokens
+	}
+	if len(current) > 0 {
+		flush()
+	}
+	return chunks
+}
+
+// overlapTail 은 청크 끝에서부터 overlap 토큰을 넘지 않는 조각들을 고릅니다. 청크 전체를 다시 넣지는 않습니다.
+func overlapTail(chunk []segment, overlap int, section int) []segment {
+	start, tokens := len(chunk), 0
+	for start > 1 {
+		seg := chunk[start-1]
+		if seg.section != section || tokens+seg.tokens > overlap {
+			break
+		}
+		tokens += seg.tokens
+		start--
+	}
+	return append([]segment(nil), chunk[start:]...)
+}
+
+var paragraphBreak = regexp.MustCompile(`\n[ \t]*\n\s*`)
+
+func splitParagraphs(text string) []string {
+	return splitAfter(text, paragraphBreak.FindAllStringIndex(text, -1))
+}
+
+// splitSentences 는 마침표/물음표/느낌표(전각 포함) 뒤에 공백이 오거나 줄이 바뀌는 곳에서 자릅니다.
+// 닫는 따옴표와 괄호는 앞 문장에 붙입니다.
+func splitSentences(text string) []string {
+	var pieces []string
+	runes := []rune(text)
+	start := 0
+	for i := 0; i < len(runes); i++ {
+		end := -1
+		switch {
+		case runes[i] == '\n':
+			end = i + 1
+		case strings.ContainsRune(".!?。！？", runes[i]):
+			j := i + 1
+			for j < len(runes) && strings.ContainsRune(".!?。！？\"'”’)]」』", runes[j]) {
+				j++
+			}
+			if j == len(runes) || unicode.IsSpace(runes[j]) || strings.ContainsRune("。！？", runes[i]) {
+				end = j
+			}
+		}
+		if end < 0 {
+			continue
+		}
+		for end < len(runes) && unicode.IsSpace(runes[end]) {
+			end++
+		}
+		pieces = append(pieces, string(runes[start:end]))
+		start, i = end, end-1
+	}
+	if start < len(runes) {
+		pieces = append(pieces, string(runes[start:]))
+	}
+	return pieces
+}
+
+var wordPattern = regexp.MustCompile(`\s*\S+\s*`)
+
+func splitWords(text string) []string {
+	return wordPattern.FindAllString(text, -1)
+}
+
+// splitRunes 는 공백 없이 이어진 긴 글자열을 size 룬씩 자릅니다.
+func splitRunes(text string, size int) []string {
+	var pieces []string
+	runes := []rune(text)
+	for start := 0; start < len(runes); start += size {
+		pieces = append(pieces, string(runes[start:min(start+size, len(runes))]))
+	}
+	return pieces
+}
+
+// splitAfter 는 각 구분자 구간의 끝에서 text 를 자릅니다. 구분자는 앞 조각에 남습니다.
+func splitAfter(text string, separators [][]int) []string {
+	var pieces []string
+	start := 0
+	for _, separator := range separators {
+		pieces = append(pieces, text[start:separator[1]])
+		start = separator[1]
+	}
+	if start < len(text) {
+		pieces = append(pieces, text[start:])
+	}
+	return pieces
+}
@@ -0,0 +1,227 @@
+package chunker
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func chunkTexts(chunks []Chunk) []string {
+	texts := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		texts[i] = chunk.Text
+	}
+	return texts
+}
+
+func TestPack(t *testing.T) {
+	seg := func(text string, section int, tokens int) segment {
+		return segment{text: text, heading: strings.Repeat("h", section+1), section: section, tokens: tokens}
+	}
+
+	tests := []struct {
+		name     string
+		segments []segment
+		max      int
+		overlap  int
+		want     []string
+		headings []string
+	}{
+		{
+			name:     "겹침 없이 채움",
+			segments: []segment{seg("a ", 0, 2), seg("b ", 0, 2), seg("c ", 0, 2)},
+			max:      4,
+			want:     []string{"a b", "c"},
+			headings: []string{"h", "h"},
+		},
+		{
+			name:     "앞 청크의 끝 조각을 다시 넣음",
+			segments: []segment{seg("a ", 0, 2), seg("b ", 0, 2), seg("c ", 0, 2)},
+			max:      4,
+			overlap:  2,
+			want:     []string{"a b", "b c"},
+			headings: []string{"h", "h"},
+		},
+		{
+			name:     "겹침을 넣으면 넘치는 조각은 겹침 없이 시작",
+			segments: []segment{seg("a ", 0, 2), seg("b ", 0, 2), seg("c ", 0, 3)},
+			max:      4,
+			overlap:  2,
+			want:     []string{"a b", "c"},
+			headings: []string{"h", "h"},
+		},
+		{
+			name:     "구역이 바뀌면 자르고 겹치지 않음",
+			segments: []segment{seg("a ", 0, 1), seg("b ", 1, 1), seg("c ", 1, 1)},
+			max:      10,
+			overlap:  5,
+			want:     []string{"a", "b c"},
+			headings: []string{"h", "hh"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := pack(tt.segments, tt.max, tt.overlap)
+			if got := chunkTexts(chunks); !slices.Equal(got, tt.want) {
+				t.Fatalf("pack() = %q, want %q", got, tt.want)
+			}
+			for i, chunk := range chunks {
+				if chunk.Index != i || chunk.Heading != tt.headings[i] {
+					t.Errorf("chunk %d: Index=%d Heading=%q, want Index=%d Heading=%q", i, chunk.Index, chunk.Heading, i, tt.headings[i])
+				}
+			}
+		})
+	}
+}
+
+func TestOverlapTail(t *testing.T) {
+	chunk := []segment{
+		{text: "a", tokens: 2},
+		{text: "b", tokens: 2},
+		{text: "c", tokens: 1},
+	}
+	tests := []struct {
+		name    string
+		chunk   []segment
+		overlap int
+		section int
+		want    []string
+	}{
+		{name: "겹침 0", chunk: chunk, overlap: 0, want: nil},
+		{name: "토큰 안의 끝 조각", chunk: chunk, overlap: 3, want: []string{"b", "c"}},
+		{name: "청크 전체는 넣지 않음", chunk: chunk, overlap: 100, want: []string{"b", "c"}},
+		{name: "조각 하나짜리 청크", chunk: chunk[:1], overlap: 100, want: nil},
+		{name: "다른 구역", chunk: chunk, overlap: 100, section: 1, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, seg := range overlapTail(tt.chunk, tt.overlap, tt.section) {
+				got = append(got, seg.text)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("overlapTail() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		opts Options
+		want []string
+	}{
+		{
+			name: "빈 본문",
+			text: " \n\n ",
+			opts: Options{Strategy: StrategyParagraph},
+			want: nil,
+		},
+		{
+			name: "짧은 문단은 한 청크",
+			text: "first para\n\nsecond para",
+			opts: Options{Strategy: StrategyParagraph, MaxTokens: 100},
+			want: []string{"first para\n\nsecond para"},
+		},
+		{
+			name: "긴 문단은 문장으로 나눔",
+			text: "One two. Three four. Five six.",
+			opts: Options{Strategy: StrategyParagraph, MaxTokens: 4},
+			want: []string{"One two.", "Three four.", "Five six."},
+		},
+		{
+			name: "문장 전략의 겹침",
+			text: "Aa bb. Cc dd. Ee ff.",
+			opts: Options{Strategy: StrategySentence, MaxTokens: 6, OverlapTokens: 3},
+			want: []string{"Aa bb. Cc dd.", "Cc dd. Ee ff."},
+		},
+		{
+			name: "한도보다 긴 단어는 글자 단위로 자름",
+			text: "abcdefghijklmno",
+			opts: Options{Strategy: StrategyFixed, MaxTokens: 2},
+			want: []string{"abcdef", "ghijkl", "mno"},
+		},
+		{
+			name: "Markdown 구역 경계",
+			text: "# A\nalpha\n# B\nbeta",
+			opts: Options{Strategy: StrategyMarkdown, MaxTokens: 100, OverlapTokens: 50},
+			want: []string{"# A\nalpha", "# B\nbeta"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks, err := Split(tt.text, tt.opts)
+			if err != nil {
+				t.Fatalf("Split() error = %v", err)
+			}
+			if got := chunkTexts(chunks); !slices.Equal(got, tt.want) {
+				t.Fatalf("Split() = %q, want %q", got, tt.want)
+			}
+			for _, chunk := range chunks {
+				if max := tt.opts.MaxTokens; max > 0 && chunk.Tokens > max {
+					t.Errorf("chunk %d has %d tokens, max %d", chunk.Index, chunk.Tokens, max)
+				}
+			}
+		})
+	}
+}
+
+func TestSplitMarkdownHeadings(t *testing.T) {
+	chunks, err := Split("intro\n# A\n## B\ntext\n```\n# not a heading\n```\n", Options{Strategy: StrategyMarkdown})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var headings []string
+	for _, chunk := range chunks {
+		headings = append(headings, chunk.Heading)
+	}
+	if want := []string{"", "A", "A > B"}; !slices.Equal(headings, want) {
+		t.Errorf("headings = %q, want %q", headings, want)
+	}
+}
+
+func TestSplitLocate(t *testing.T) {
+	text := "first para\n\nsecond para\nline two\n\fthird page"
+	chunks, err := Split(text, Options{Strategy: StrategyParagraph, MaxTokens: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type position struct{ start, end, page int }
+	want := map[string]position{
+		"first para":  {1, 1, 1},
+		"second para": {3, 3, 1},
+		"line two":    {4, 4, 1},
+		"third page":  {5, 5, 2},
+	}
+	if len(chunks) != len(want) {
+		t.Fatalf("Split() = %q, want %d chunks", chunkTexts(chunks), len(want))
+	}
+	for _, chunk := range chunks {
+		pos, ok := want[chunk.Text]
+		if !ok {
+			t.Errorf("unexpected chunk %q", chunk.Text)
+			continue
+		}
+		if got := (position{chunk.StartLine, chunk.EndLine, chunk.Page}); got != pos {
+			t.Errorf("chunk %q at %+v, want %+v", chunk.Text, got, pos)
+		}
+	}
+}
+
+func TestSplitOptionErrors(t *testing.T) {
+	if _, err := Split("text", Options{Strategy: StrategyFixed, MaxTokens: 10, OverlapTokens: 10}); err == nil {
+		t.Error("overlap >= max: want error")
+	}
+	if _, err := Split("text", Options{Strategy: "words"}); err == nil {
+		t.Error("unknown strategy: want error")
+	}
+	if _, err := ParseStrategy("words"); err == nil {
+		t.Error("ParseStrategy(unknown): want error")
+	}
+}
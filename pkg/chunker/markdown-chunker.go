@@ -0,0 +1,78 @@
+package chunker
+
+import (
+	"regexp"
+	"strings"
+)
+
+type markdownSection struct {
+	heading string
+	text    string
+}
+
+var markdownHeading = regexp.MustCompile(`^ {0,3}(#{1,6})[ \t]+(.*?)[ \t#]*$`)
+
+// splitMarkdownSections 는 제목 줄마다 새 구역을 시작합니다. 구역의 heading 은 상위 제목까지 이은 경로이고,
+// 코드 블록(```, ~~~) 안의 # 줄은 제목으로 보지 않습니다. 첫 제목 앞의 본문은 제목 없는 구역이 됩니다.
+func splitMarkdownSections(text string) []markdownSection {
+	var sections []markdownSection
+	var headings [6]string
+	current := markdownSection{}
+	var builder strings.Builder
+	fence := ""
+
+	for _, line := range strings.SplitAfter(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if marker := fenceMarker(trimmed); marker != "" {
+			switch {
+			case fence == "":
+				fence = marker
+			case strings.HasPrefix(trimmed, fence):
+				fence = ""
+			}
+		}
+
+		if match := markdownHeading.FindStringSubmatch(strings.TrimRight(line, "\r\n")); fence == "" && match != nil {
+			current.text = builder.String()
+			sections = append(sections, current)
+			builder.Reset()
+
+			level := len(match[1])
+			headings[level-1] = strings.TrimSpace(match[2])
+			for i := level; i < len(headings); i++ {
+				headings[i] = ""
+			}
+			current = markdownSection{heading: joinHeadings(headings[:level])}
+		}
+		builder.WriteString(line)
+	}
+	current.text = builder.String()
+	sections = append(sections, current)
+
+	nonEmpty := sections[:0]
+	for _, section := range sections {
+		if strings.TrimSpace(section.text) != "" {
+			nonEmpty = append(nonEmpty, section)
+		}
+	}
+	return nonEmpty
+}
+
+func fenceMarker(line string) string {
+	for _, marker := range []string{"```", "~~~"} {
+		if strings.HasPrefix(line, marker) {
+			return marker
+		}
+	}
+	return ""
+}
+
+func joinHeadings(headings []string) string {
+	var path []string
+	for _, heading := range headings {
+		if heading != "" {
+			path = append(path, heading)
+		}
+	}
+	return strings.Join(path, " > ")
+}
@@ -0,0 +1,233 @@
+package ontology
+
+import (
+	"fmt"
+	"github.com/JCSong-89/trpg-rag-game/pkg/types"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Action 은 스키마에 맞지 않는 항목을 어떻게 처리했는지입니다.
+type Action string
+
+const (
+	// ActionRemapped 는 항목을 스키마에 맞게 바꿔 남겼다는 뜻입니다.
+	ActionRemapped Action = "remapped"
+	// ActionRejected 는 항목을 버렸다는 뜻입니다.
+	ActionRejected Action = "rejected"
+)
+
+// Issue 는 스키마 검사에서 걸린 항목 하나입니다. Target 은 엔티티 ID 나 관계("a-[:TYPE]->b")입니다.
+type Issue struct {
+	Action Action
+	Target string
+	Detail string
+}
+
+func (i Issue) String() string {
+	action := "바꿈"
+	if i.Action == ActionRejected {
+		action = "버림"
+	}
+	return fmt.Sprintf("[%s] %s: %s", action, i.Target, i.Detail)
+}
+
+// Report 는 Normalize 가 바꾸거나 버린 항목 목록입니다.
+type Report struct {
+	Issues []Issue
+}
+
+func (r *Report) add(action Action, target string, format string, args ...any) {
+	r.Issues = append(r.Issues, Issue{Action: action, Target: target, Detail: fmt.Sprintf(format, args...)})
+}
+
+// Count 는 action 으로 처리한 항목 수입니다.
+func (r Report) Count(action Action) int {
+	count := 0
+	for _, issue := range r.Issues {
+		if issue.Action == action {
+			count++
+		}
+	}
+	return count
+}
+
+func (r Report) String() string {
+	return fmt.Sprintf("바꿈 %d건, 버림 %d건", r.Count(ActionRemapped), r.Count(ActionRejected))
+}
+
+// Normalize 는 추출 결과를 스키마에 맞춥니다.
+//   - 라벨과 관계 타입은 이름이나 별칭으로 찾아 스키마의 이름으로 바꾸고, 없으면 기본값으로 바꾸거나 버립니다.
+//   - 선언된 속성은 키 표기를 맞추고 값을 선언된 타입으로 바꾸며, 바꿀 수 없는 값과 선언되지 않은 속성은 버립니다.
+//   - 관계의 양 끝 라벨이 Domain/Range 에 맞지 않으면, 방향을 뒤집어 맞을 때만 뒤집고 그 밖에는 버립니다.
+//     끝 엔티티가 data 에 없으면(이미 저장된 엔티티) 라벨을 알 수 없으므로 검사하지 않습니다.
+//   - 버려진 엔티티에 걸린 관계도 버립니다.
+func (o *Ontology) Normalize(data types.ParsedData) (types.ParsedData, Report) {
+	var report Report
+	labelsByID := map[string]string{}
+	rejectedIDs := map[string]bool{}
+
+	var entities []types.Entity
+	for _, entity := range data.Entities {
+		label, ok := o.Label(entity.Label)
+		if !ok && o.DefaultLabel != "" {
+			label, _ = o.Label(o.DefaultLabel)
+		}
+		if label == nil {
+			report.add(ActionRejected, entity.ID, "스키마에 없는 라벨 %q", entity.Label)
+			rejectedIDs[entity.ID] = true
+			continue
+		}
+		if label.Name != entity.Label {
+			report.add(ActionRemapped, entity.ID, "라벨 %q → %s", entity.Label, label.Name)
+			entity.Label = label.Name
+		}
+		entity.Properties = o.normalizeProperties(entity.ID, label, entity.Properties, &report)
+		labelsByID[entity.ID] = entity.Label
+		entities = append(entities, entity)
+	}
+
+	var relations []types.Relation
+	for _, relation := range data.Relations {
+		target := fmt.Sprintf("%s-[:%s]->%s", relation.SourceName, relation.Type, relation.TargetName)
+		if rejectedIDs[relation.SourceName] || rejectedIDs[relation.TargetName] {
+			report.add(ActionRejected, target, "끝 엔티티가 스키마에 맞지 않아 버려졌습니다")
+			continue
+		}
+
+		relationType, ok := o.Relation(relation.Type)
+		if !ok && o.DefaultRelation != "" {
+			relationType, _ = o.Relation(o.DefaultRelation)
+		}
+		if relationType == nil {
+			report.add(ActionRejected, target, "스키마에 없는 관계 타입 %q", relation.Type)
+			continue
+		}
+
+		sourceLabel, sourceKnown := labelsByID[relation.SourceName]
+		targetLabel, targetKnown := labelsByID[relation.TargetName]
+		fits := func(source string, sourceKnown bool, target string, targetKnown bool) bool {
+			return (!sourceKnown || allows(relationType.Domain, source)) && (!targetKnown || allows(relationType.Range, target))
+		}
+		switch {
+		case fits(sourceLabel, sourceKnown, targetLabel, targetKnown):
+		case fits(targetLabel, targetKnown, sourceLabel, sourceKnown):
+			report.add(ActionRemapped, target, "%s 는 (%s)->(%s) 에 쓸 수 없어 방향을 뒤집었습니다", relationType.Name, sourceLabel, targetLabel)
+			relation.SourceName, relation.TargetName = relation.TargetName, relation.SourceName
+		default:
+			report.add(ActionRejected, target, "%s 는 (%s)->(%s) 사이에 쓸 수 없습니다", relationType.Name, sourceLabel, targetLabel)
+			continue
+		}
+
+		if relationType.Name != relation.Type {
+			report.add(ActionRemapped, target, "관계 타입 %q → %s", relation.Type, relationType.Name)
+			relation.Type = relationType.Name
+		}
+		relations = append(relations, relation)
+	}
+
+	return types.ParsedData{Entities: entities, Relations: relations}, report
+}
+
+// normalizeProperties 는 라벨에 선언된 속성만 선언된 키와 타입으로 남깁니다. 선언이 없는 라벨은 그대로 둡니다.
+func (o *Ontology) normalizeProperties(entityID string, label *Label, properties map[string]any, report *Report) map[string]any {
+	if len(label.Properties) == 0 || len(properties) == 0 {
+		return properties
+	}
+
+	declared := make(map[string]string, len(label.Properties))
+	for name := range label.Properties {
+		declared[normalizeName(name)] = name
+	}
+
+	keys := make([]string, 0, len(properties))
+	for key := range properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	normalized := make(map[string]any, len(properties))
+	for _, key := range keys {
+		value := properties[key]
+		name, ok := declared[normalizeName(key)]
+		if !ok {
+			if o.ExtraProperties {
+				normalized[key] = value
+			} else {
+				report.add(ActionRejected, entityID, "%s 에 선언되지 않은 속성 %q", label.Name, key)
+			}
+			continue
+		}
+
+		converted, err := convertProperty(value, label.Properties[name])
+		if err != nil {
+			report.add(ActionRejected, entityID, "속성 %s: %v", name, err)
+			continue
+		}
+		if name != key {
+			report.add(ActionRemapped, entityID, "속성 키 %q → %s", key, name)
+		}
+		normalized[name] = converted
+	}
+	return normalized
+}
+
+// convertProperty 는 JSON 에서 읽은 값(string, float64, bool, []any)을 propertyType 으로 바꿉니다.
+func convertProperty(value any, propertyType PropertyType) (any, error) {
+	switch propertyType {
+	case PropertyString:
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		case float64, bool:
+			return fmt.Sprint(v), nil
+		}
+	case PropertyInt:
+		switch v := value.(type) {
+		case float64:
+			if v == math.Trunc(v) {
+				return int64(v), nil
+			}
+		case string:
+			if n, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64); err == nil {
+				return n, nil
+			}
+		}
+	case PropertyFloat:
+		switch v := value.(type) {
+		case float64:
+			return v, nil
+		case string:
+			if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+				return f, nil
+			}
+		}
+	case PropertyBool:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			if b, err := strconv.ParseBool(strings.TrimSpace(v)); err == nil {
+				return b, nil
+			}
+		}
+	case PropertyList:
+		switch v := value.(type) {
+		case string:
+			return []any{v}, nil
+		case []any:
+			list := make([]any, 0, len(v))
+			for _, item := range v {
+				s, ok := item.(string)
+				if !ok {
+					return nil, fmt.Errorf("값 %v 을 %s 로 바꿀 수 없습니다", value, propertyType)
+				}
+				list = append(list, s)
+			}
+			return list, nil
+		}
+	}
+	return nil, fmt.Errorf("값 %v 을 %s 로 바꿀 수 없습니다", value, propertyType)
+}
@@ -0,0 +1,171 @@
+package ontology
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// PropertyType 은 속성 값의 타입입니다.
+type PropertyType string
+
+const (
+	PropertyString PropertyType = "string"
+	PropertyInt    PropertyType = "int"
+	PropertyFloat  PropertyType = "float"
+	PropertyBool   PropertyType = "bool"
+	// PropertyList 는 문자열 목록입니다.
+	PropertyList PropertyType = "list"
+)
+
+// Label 은 허용하는 엔티티 라벨입니다. Aliases 는 모델이 쓸 법한 다른 표기(예: "Player", "선수")로, 이 라벨로 바꿉니다.
+// Properties 가 비어 있으면 이 라벨의 속성은 검사하지 않습니다.
+type Label struct {
+	Name        string                  `json:"name"`
+	Description string                  `json:"description,omitempty"`
+	Aliases     []string                `json:"aliases,omitempty"`
+	Properties  map[string]PropertyType `json:"properties,omitempty"`
+}
+
+// RelationType 은 허용하는 관계 타입입니다. Domain 은 출발 엔티티, Range 는 도착 엔티티에 허용하는 라벨이며, 비어 있으면 제한이 없습니다.
+type RelationType struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Aliases     []string `json:"aliases,omitempty"`
+	Domain      []string `json:"domain,omitempty"`
+	Range       []string `json:"range,omitempty"`
+}
+
+// Ontology 는 추출 결과에 허용하는 라벨과 관계 타입입니다. 라벨과 관계 타입 이름은 대소문자와 구분자(공백, _, -)를
+// 무시하고 찾습니다. DefaultLabel, DefaultRelation 은 스키마에 없는 항목을 바꿔 넣을 이름이며, 비어 있으면 그 항목을 버립니다.
+// ExtraProperties 가 true 면 라벨에 선언하지 않은 속성도 남깁니다.
+type Ontology struct {
+	Labels          []Label        `json:"labels"`
+	Relations       []RelationType `json:"relations"`
+	DefaultLabel    string         `json:"defaultLabel,omitempty"`
+	DefaultRelation string         `json:"defaultRelation,omitempty"`
+	ExtraProperties bool           `json:"extraProperties,omitempty"`
+
+	labels    map[string]*Label
+	relations map[string]*RelationType
+}
+
+// Cypher 에 그대로 들어가므로 라벨과 관계 타입 이름은 영문 식별자만 허용합니다.
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Load 는 JSON 온톨로지 파일을 읽고 검사합니다.
+func Load(path string) (*Ontology, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("온톨로지 파일 읽기 실패 (%s): %w", path, err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	var ontology Ontology
+	if err := decoder.Decode(&ontology); err != nil {
+		return nil, fmt.Errorf("온톨로지 파일 해석 실패 (%s): %w", path, err)
+	}
+	if err := ontology.compile(); err != nil {
+		return nil, fmt.Errorf("온톨로지가 올바르지 않습니다 (%s): %w", path, err)
+	}
+	return &ontology, nil
+}
+
+// compile 은 이름과 별칭으로 찾는 색인을 만들고, 이름 형식, 중복, 속성 타입, Domain/Range 가 가리키는 라벨을 검사합니다.
+func (o *Ontology) compile() error {
+	if len(o.Labels) == 0 {
+		return fmt.Errorf("라벨이 하나도 없습니다")
+	}
+
+	o.labels = map[string]*Label{}
+	for i := range o.Labels {
+		label := &o.Labels[i]
+		if !identifierPattern.MatchString(label.Name) {
+			return fmt.Errorf("라벨 이름은 영문 식별자여야 합니다: %q", label.Name)
+		}
+		for _, name := range append([]string{label.Name}, label.Aliases...) {
+			key := normalizeName(name)
+			if existing, ok := o.labels[key]; ok && existing != label {
+				return fmt.Errorf("라벨 %s 와 %s 가 같은 이름(%s)을 씁니다", existing.Name, label.Name, name)
+			}
+			o.labels[key] = label
+		}
+		for property, propertyType := range label.Properties {
+			switch propertyType {
+			case PropertyString, PropertyInt, PropertyFloat, PropertyBool, PropertyList:
+			default:
+				return fmt.Errorf("라벨 %s 의 속성 %s 타입을 알 수 없습니다: %s", label.Name, property, propertyType)
+			}
+		}
+	}
+
+	o.relations = map[string]*RelationType{}
+	for i := range o.Relations {
+		relation := &o.Relations[i]
+		if !identifierPattern.MatchString(relation.Name) {
+			return fmt.Errorf("관계 타입 이름은 영문 식별자여야 합니다: %q", relation.Name)
+		}
+		for _, name := range append([]string{relation.Name}, relation.Aliases...) {
+			key := normalizeName(name)
+			if existing, ok := o.relations[key]; ok && existing != relation {
+				return fmt.Errorf("관계 타입 %s 와 %s 가 같은 이름(%s)을 씁니다", existing.Name, relation.Name, name)
+			}
+			o.relations[key] = relation
+		}
+		for _, labels := range [][]string{relation.Domain, relation.Range} {
+			for j, name := range labels {
+				label, ok := o.Label(name)
+				if !ok {
+					return fmt.Errorf("관계 타입 %s 가 없는 라벨을 가리킵니다: %s", relation.Name, name)
+				}
+				labels[j] = label.Name
+			}
+		}
+	}
+
+	if o.DefaultLabel != "" {
+		if _, ok := o.Label(o.DefaultLabel); !ok {
+			return fmt.Errorf("기본 라벨이 라벨 목록에 없습니다: %s", o.DefaultLabel)
+		}
+	}
+	if o.DefaultRelation != "" {
+		if _, ok := o.Relation(o.DefaultRelation); !ok {
+			return fmt.Errorf("기본 관계 타입이 관계 타입 목록에 없습니다: %s", o.DefaultRelation)
+		}
+	}
+	return nil
+}
+
+// Label 은 이름이나 별칭이 name 인 라벨을 찾습니다.
+func (o *Ontology) Label(name string) (*Label, bool) {
+	label, ok := o.labels[normalizeName(name)]
+	return label, ok
+}
+
+// Relation 은 이름이나 별칭이 name 인 관계 타입을 찾습니다.
+func (o *Ontology) Relation(name string) (*RelationType, bool) {
+	relation, ok := o.relations[normalizeName(name)]
+	return relation, ok
+}
+
+// allows 는 labels 가 비어 있거나 label 이 labels 중 하나일 때 true 입니다. Domain, Range 는 compile 에서 라벨 이름으로 바뀌어 있습니다.
+func allows(labels []string, label string) bool {
+	return len(labels) == 0 || slices.Contains(labels, label)
+}
+
+// normalizeName 은 대소문자와 글자/숫자가 아닌 문자를 무시한 비교용 이름입니다. 예: "Plays-For" → "playsfor"
+func normalizeName(name string) string {
+	var sb strings.Builder
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return sb.String()
+}
@@ -1,13 +1,153 @@
 package types
 
+import "time"
+
+// DbConfig 의 GraphStore 는 "neo4j"(기본값) 또는 "memory", VectorStore 는 "qdrant"(기본값) 또는 "memory" 입니다.
+// memory 저장소는 GraphStoreDir, VectorStoreDir 에 저장해 ingest 와 query 가 따로 실행되어도 같은 데이터를 봅니다.
+// 환경 변수가 없으면 .graph, .vectors 를 쓰며, 두 값이 비어 있으면(테스트 등) 디스크에 저장하지 않습니다.
+// Dataset 은 캠페인 단위 데이터셋 이름으로, 그래프 노드의 dataset 속성과 벡터 컬렉션 이름으로 쓰입니다.
 type DbConfig struct {
-	Neo4jUrl    string
-	Neo4jUser   string
-	Neo4jPass   string
-	QuadrantUrI string
+	Neo4jUrl       string
+	Neo4jUser      string
+	Neo4jPass      string
+	QuadrantUrI    string
+	GraphStore     string
+	GraphStoreDir  string
+	VectorStore    string
+	VectorStoreDir string
+	Dataset        string
+}
+
+// HTTPConfig 는 외부 모델 API 호출에 쓰는 HTTP 클라이언트 설정입니다.
+// 0 은 기본값을 뜻하고, MaxRetries/BreakerThreshold 에 음수를 주면 해당 기능을 끕니다.
+// FixtureMode 는 "record" 또는 "replay" 이며, 비어 있으면 실제 API 를 그대로 호출합니다.
+type HTTPConfig struct {
+	Timeout          time.Duration
+	MaxRetries       int
+	BaseBackoff      time.Duration
+	MaxBackoff       time.Duration
+	RatePerSecond    float64
+	Burst            int
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+	FixtureMode      string
+	FixtureDir       string
+}
+
+// ModelPrice 는 100만 토큰당 USD 가격입니다.
+type ModelPrice struct {
+	InputPerMillion  float64
+	OutputPerMillion float64
+}
+
+// ResponseCacheConfig 는 같은 프롬프트에 대한 LLM 응답 재사용 설정입니다.
+// Dir 가 비어 있으면 메모리 LRU 만 사용합니다.
+type ResponseCacheConfig struct {
+	Enabled bool
+	Size    int
+	TTL     time.Duration
+	Dir     string
+}
+
+// LLMRoute 는 단계별 라우팅 목록의 후보 하나입니다. Model 이 비어 있으면 프로바이더의 기본 모델을 씁니다.
+type LLMRoute struct {
+	Provider string
+	Model    string
+}
+
+// LLMBackend 는 라우팅에 등장하는 프로바이더의 접속 정보입니다.
+type LLMBackend struct {
+	BaseURL string
+	APIKey  string
+}
+
+type LLMConfig struct {
+	Provider    string
+	Model       string
+	BaseURL     string
+	APIKey      string
+	Temperature *float64
+	TopP        *float64
+	MaxTokens   int
+	// SystemPrompt 는 호출에서 WithSystemPrompt 로 바꾸지 않는 한 모든 호출에 붙는 시스템 지시문입니다.
+	SystemPrompt string
+	// SafetySettings 는 Gemini 에만 적용됩니다.
+	SafetySettings []GeminiSafetySetting
+	ScriptPath     string
+	HTTP           HTTPConfig
+	Prices         map[string]ModelPrice
+	Cache          ResponseCacheConfig
+	Routes         map[string][]LLMRoute
+	Backends       map[string]LLMBackend
+}
+
+type EmbeddingConfig struct {
+	Provider  string
+	Model     string
+	BaseURL   string
+	APIKey    string
+	Dimension int
+	CachePath string
+	HTTP      HTTPConfig
+}
+
+// IngestConfig 의 ConflictPolicy 는 같은 entityId 로 다시 적재할 때 속성 충돌을 처리하는 방식입니다.
+// ManifestDir 에는 데이터셋마다 적재한 문서 기록(<데이터셋>.jsonl)을 남기며, 비어 있으면 기록하지 않습니다.
+// ChunkStrategy 는 "fixed", "sentence", "paragraph", "markdown" 중 하나이며, 비어 있으면
+// Markdown 문서는 "markdown", 그 밖의 문서는 "paragraph" 를 씁니다. ChunkTokens, ChunkOverlap 은 어림 토큰 수입니다.
+// OntologyPath 는 추출 결과를 제한할 온톨로지(JSON) 파일 경로이며, 비어 있으면 라벨과 관계 타입을 제한하지 않습니다.
+type IngestConfig struct {
+	BatchSize      int
+	Concurrency    int
+	ConflictPolicy ConflictPolicy
+	ManifestDir    string
+	ChunkStrategy  string
+	ChunkTokens    int
+	ChunkOverlap   int
+	Resolution     ResolutionConfig
+	OntologyPath   string
+}
+
+// ResolutionConfig 는 적재할 때 같은 대상을 가리키는 엔티티를 하나로 합치는 설정입니다. 라벨이 호환될 때만 합칩니다.
+// 이름 표기(대소문자, 구분자, 한글 로마자 표기)가 같으면 바로 합치고, 임베딩 유사도가 Similarity 이상이어도 합칩니다.
+// 한쪽 이름이 다른 쪽 이름의 일부일 때(예: "Son" 과 "Son Heung-min")는 유사도가 AliasSimilarity 이상이면 합칩니다.
+// CandidateTopK 는 이미 저장된 엔티티 중 벡터 검색으로 비교할 후보 수입니다.
+type ResolutionConfig struct {
+	Enabled         bool
+	Similarity      float64
+	AliasSimilarity float64
+	CandidateTopK   int
+}
+
+// ConflictPolicy 는 이미 저장된 엔티티에 같은 속성이 다른 값으로 들어올 때의 처리 방식입니다.
+type ConflictPolicy string
+
+const (
+	// ConflictOverwrite 는 새 값으로 덮어씁니다.
+	ConflictOverwrite ConflictPolicy = "overwrite"
+	// ConflictKeep 은 기존 값을 유지하고 없던 속성만 추가합니다.
+	ConflictKeep ConflictPolicy = "keep"
+)
+
+// ConversationConfig 는 대화 이력 관리 설정입니다. 이력이 TokenBudget 을 넘으면
+// 최근 KeepMessages 개를 제외한 오래된 턴을 요약으로 접습니다.
+type ConversationConfig struct {
+	TokenBudget  int
+	KeepMessages int
+}
+
+// AgentConfig 의 MaxSteps 는 최종 답변 단계에서 도구 호출을 반복할 최대 횟수입니다(기본값 6).
+// 0 이하로 설정하면 에이전트를 끄고 도구 없이 답하며, 이때는 답변을 스트리밍으로 출력합니다.
+type AgentConfig struct {
+	MaxSteps int
 }
 
 type Config struct {
-	ServerPort string
-	Db         DbConfig
-}
\ No newline at end of file
+	ServerPort   string
+	Db           DbConfig
+	LLM          LLMConfig
+	Embedding    EmbeddingConfig
+	Ingest       IngestConfig
+	Conversation ConversationConfig
+	Agent        AgentConfig
+}
@@ -4,17 +4,28 @@ type Entity struct {
 	ID         string
 	Name       string
 	Label      string
-	Embedding  []float32
+	Embedding  []float32 `jsonschema:"-"`
 	Properties map[string]any
+	// Aliases 는 같은 대상을 가리키는 다른 이름(별명, 음역, 줄임말)입니다. 모델이 채울 수 있고, 엔티티 해소 단계에서 더해집니다.
+	Aliases []string `json:",omitempty"`
+	// Sources 는 엔티티가 언급된 청크 id 목록입니다. 모델이 아니라 적재 단계에서 채웁니다.
+	Sources []string `jsonschema:"-"`
 }
 
 type Relation struct {
 	SourceName string
 	TargetName string
 	Type       string
+	// Sources 는 관계가 언급된 청크 id 목록입니다. 모델이 아니라 적재 단계에서 채웁니다.
+	Sources []string `jsonschema:"-"`
+}
+
+// Key 는 출발, 타입, 도착으로 관계를 구분하는 값입니다. Sources 는 보지 않습니다.
+func (r Relation) Key() string {
+	return r.SourceName + "\x00" + r.Type + "\x00" + r.TargetName
 }
 
 type ParsedData struct {
 	Entities  []Entity   `json:"entities"`
 	Relations []Relation `json:"relations"`
-}
\ No newline at end of file
+}
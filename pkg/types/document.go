@@ -0,0 +1,43 @@
+package types
+
+import "fmt"
+
+// Document 는 적재할 원문 하나입니다. ID 는 파일 경로이며, JSON lines 파일은 줄마다 "경로#줄번호" 문서가 됩니다.
+// Hash 는 Text 의 SHA-256 으로, 내용이 바뀌었는지 확인하는 데 씁니다.
+type Document struct {
+	ID     string
+	Source string
+	Format string
+	Title  string
+	Text   string
+	Hash   string
+}
+
+// SourceChunk 는 엔티티와 관계의 출처가 되는 문서 조각입니다. ID 는 "<문서 ID>#chunk-<순번>" 입니다.
+// StartLine, EndLine 은 문서 본문 기준 줄 번호(1부터)이고, Page 는 본문에 쪽 나눔 문자(\f)가 있을 때만 1 이상입니다.
+type SourceChunk struct {
+	ID         string `json:"id"`
+	DocumentID string `json:"documentId"`
+	Source     string `json:"source"`
+	Index      int    `json:"index"`
+	Heading    string `json:"heading,omitempty"`
+	Text       string `json:"text"`
+	StartLine  int    `json:"startLine"`
+	EndLine    int    `json:"endLine"`
+	Page       int    `json:"page,omitempty"`
+}
+
+// Citation 은 답변에 붙이는 출처 표기입니다. 예: "rules.md p.3 L12-30 (전투 > 기회 공격)"
+func (c SourceChunk) Citation() string {
+	citation := c.DocumentID
+	if c.Page > 0 {
+		citation += fmt.Sprintf(" p.%d", c.Page)
+	}
+	if c.StartLine > 0 {
+		citation += fmt.Sprintf(" L%d-%d", c.StartLine, c.EndLine)
+	}
+	if c.Heading != "" {
+		citation += " (" + c.Heading + ")"
+	}
+	return citation
+}
@@ -2,4 +2,18 @@ package types
 
 type EmbeddingRequest struct {
 	Inputs []string `json:"inputs"`
-}
\ No newline at end of file
+}
+
+type OpenAIEmbeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type OpenAIEmbeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+	Model string    `json:"model"`
+	Usage GrokUsage `json:"usage"`
+}
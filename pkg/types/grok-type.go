@@ -1,10 +1,44 @@
 package types
 
 type GrokMessage struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role       string         `json:"role"`
+	Content    string         `json:"content"`
+	ToolCalls  []GrokToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string         `json:"tool_call_id,omitempty"`
+}
+
+// GrokToolCall 의 Arguments 는 JSON 을 담은 문자열입니다.
+type GrokToolCall struct {
+	ID       string           `json:"id"`
+	Type     string           `json:"type"`
+	Function GrokFunctionCall `json:"function"`
+}
+
+type GrokFunctionCall struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+type GrokTool struct {
+	Type     string       `json:"type"`
+	Function GrokFunction `json:"function"`
+}
+
+type GrokFunction struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Parameters  any    `json:"parameters,omitempty"`
 }
 
 type GrokChoice struct {
 	Message GrokMessage `json:"message"`
-}
\ No newline at end of file
+}
+type GrokStreamChoice struct {
+	Delta GrokMessage `json:"delta"`
+}
+
+type GrokStreamResponse struct {
+	Choices []GrokStreamChoice `json:"choices"`
+	Model   string             `json:"model"`
+	Usage   *GrokUsage         `json:"usage,omitempty"`
+}
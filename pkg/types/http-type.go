@@ -1,36 +1,134 @@
 package types
 
+import "encoding/json"
+
+// GeminiPart 는 텍스트, 함수 호출, 함수 결과 중 하나를 담습니다.
+type GeminiPart struct {
+	Text             string                  `json:"text,omitempty"`
+	FunctionCall     *GeminiFunctionCall     `json:"functionCall,omitempty"`
+	FunctionResponse *GeminiFunctionResponse `json:"functionResponse,omitempty"`
+}
+
+type GeminiFunctionCall struct {
+	ID   string          `json:"id,omitempty"`
+	Name string          `json:"name"`
+	Args json.RawMessage `json:"args,omitempty"`
+}
+
+// GeminiFunctionResponse 의 Response 는 JSON 객체여야 합니다.
+type GeminiFunctionResponse struct {
+	ID       string `json:"id,omitempty"`
+	Name     string `json:"name"`
+	Response any    `json:"response"`
+}
+
+// GeminiFunctionDeclaration 은 응답 스키마와 같은 이유로 parametersJsonSchema(표준 JSON Schema)를 사용합니다.
+type GeminiFunctionDeclaration struct {
+	Name                 string `json:"name"`
+	Description          string `json:"description,omitempty"`
+	ParametersJsonSchema any    `json:"parametersJsonSchema,omitempty"`
+}
+
+type GeminiTool struct {
+	FunctionDeclarations []GeminiFunctionDeclaration `json:"functionDeclarations"`
+}
+
+// GeminiToolConfig 의 Mode 는 AUTO, ANY, NONE 중 하나입니다.
+type GeminiToolConfig struct {
+	FunctionCallingConfig struct {
+		Mode string `json:"mode"`
+	} `json:"functionCallingConfig"`
+}
+
+// GeminiSafetySetting 은 HARM_CATEGORY_* 분류별 차단 기준(BLOCK_NONE, BLOCK_ONLY_HIGH 등)입니다.
+type GeminiSafetySetting struct {
+	Category  string `json:"category"`
+	Threshold string `json:"threshold"`
+}
+
+type GeminiContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []GeminiPart `json:"parts"`
+}
+
+// GeminiGenerationConfig 의 ResponseJsonSchema 는 표준 JSON Schema 를 받습니다.
+// responseSchema(OpenAPI 부분 집합)는 자유 형식 객체를 표현하지 못해 Properties 같은 맵 필드에 쓸 수 없습니다.
+type GeminiGenerationConfig struct {
+	Temperature        *float64 `json:"temperature,omitempty"`
+	TopP               *float64 `json:"topP,omitempty"`
+	MaxOutputTokens    int      `json:"maxOutputTokens,omitempty"`
+	ResponseMimeType   string   `json:"responseMimeType,omitempty"`
+	ResponseJsonSchema any      `json:"responseJsonSchema,omitempty"`
+}
+
 type GeminiHttpRequest struct {
-	Contents []struct {
-		Parts []struct {
-			Text string `json:"text"`
-		} `json:"parts"`
-	} `json:"contents"`
+	Contents          []GeminiContent         `json:"contents"`
+	SystemInstruction *GeminiContent          `json:"systemInstruction,omitempty"`
+	GenerationConfig  *GeminiGenerationConfig `json:"generationConfig,omitempty"`
+	SafetySettings    []GeminiSafetySetting   `json:"safetySettings,omitempty"`
+	Tools             []GeminiTool            `json:"tools,omitempty"`
+	ToolConfig        *GeminiToolConfig       `json:"toolConfig,omitempty"`
+}
+
+type GeminiUsageMetadata struct {
+	PromptTokenCount     int `json:"promptTokenCount"`
+	CandidatesTokenCount int `json:"candidatesTokenCount"`
+	TotalTokenCount      int `json:"totalTokenCount"`
 }
 
 type GeminiHttpResponse struct {
 	Candidates []struct {
-		Content struct {
-			Parts []struct {
-				Text string `json:"text"`
-			} `json:"parts"`
-		} `json:"content"`
+		Content      GeminiContent `json:"content"`
+		FinishReason string        `json:"finishReason,omitempty"`
 	} `json:"candidates"`
+	PromptFeedback *struct {
+		BlockReason string `json:"blockReason,omitempty"`
+	} `json:"promptFeedback,omitempty"`
+	UsageMetadata *GeminiUsageMetadata `json:"usageMetadata,omitempty"`
+	ModelVersion  string               `json:"modelVersion,omitempty"`
+}
+
+type GrokJSONSchema struct {
+	Name   string `json:"name"`
+	Schema any    `json:"schema"`
+	Strict bool   `json:"strict"`
+}
+
+type GrokResponseFormat struct {
+	Type       string          `json:"type"`
+	JSONSchema *GrokJSONSchema `json:"json_schema,omitempty"`
 }
 
 type GrokHttpRequest struct {
-	Messages    []GrokMessage `json:"messages"`
-	Model       string        `json:"model"`
-	Temperature float64       `json:"temperature"`
-	MaxTokens   int           `json:"max_tokens"`
-	Stream      bool          `json:"stream"`
+	Messages       []GrokMessage       `json:"messages"`
+	Model          string              `json:"model"`
+	Temperature    *float64            `json:"temperature,omitempty"`
+	TopP           *float64            `json:"top_p,omitempty"`
+	MaxTokens      int                 `json:"max_tokens,omitempty"`
+	Stream         bool                `json:"stream"`
+	ResponseFormat *GrokResponseFormat `json:"response_format,omitempty"`
+	StreamOptions  *GrokStreamOptions  `json:"stream_options,omitempty"`
+	Tools          []GrokTool          `json:"tools,omitempty"`
+	ToolChoice     string              `json:"tool_choice,omitempty"`
+}
+
+type GrokStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
 }
+
+// GrokUsage 는 chat completions 응답의 토큰 사용량입니다.
+// 서버에 따라 prompt/completion 또는 input/output 이름을 사용하므로 둘 다 받습니다.
+type GrokUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	InputTokens      int `json:"input_tokens"`
+	OutputTokens     int `json:"output_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
 type GrokHttpResponse struct {
 	ID      string       `json:"id"`
+	Model   string       `json:"model"`
 	Choices []GrokChoice `json:"choices"`
-	Usage   struct {
-		InputTokens  int `json:"input_tokens"`
-		OutputTokens int `json:"output_tokens"`
-		TotalTokens  int `json:"total_tokens"`
-	} `json:"usage"`
-}
\ No newline at end of file
+	Usage   GrokUsage    `json:"usage"`
+}
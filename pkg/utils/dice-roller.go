@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	maxDiceCount = 100
+	maxDiceSides = 1000
+)
+
+var diceNotationPattern = regexp.MustCompile(`^(\d*)d(\d+)\s*(?:([+-])\s*(\d+))?$`)
+
+// DiceRoll 은 한 번의 주사위 굴림 결과입니다.
+type DiceRoll struct {
+	Notation string `json:"notation"`
+	Rolls    []int  `json:"rolls"`
+	Modifier int    `json:"modifier"`
+	Total    int    `json:"total"`
+}
+
+// RollDice 는 "d20", "2d6+3", "4d8-1" 같은 표기를 해석해 주사위를 굴립니다.
+func RollDice(notation string) (DiceRoll, error) {
+	normalized := strings.ToLower(strings.TrimSpace(notation))
+	match := diceNotationPattern.FindStringSubmatch(normalized)
+	if match == nil {
+		return DiceRoll{}, fmt.Errorf("주사위 표기를 해석할 수 없습니다: %q (예: 2d6+3)", notation)
+	}
+
+	count := 1
+	if match[1] != "" {
+		count, _ = strconv.Atoi(match[1])
+	}
+	sides, _ := strconv.Atoi(match[2])
+	if count < 1 || count > maxDiceCount {
+		return DiceRoll{}, fmt.Errorf("주사위 개수는 1~%d 사이여야 합니다: %d", maxDiceCount, count)
+	}
+	if sides < 2 || sides > maxDiceSides {
+		return DiceRoll{}, fmt.Errorf("주사위 면 수는 2~%d 사이여야 합니다: %d", maxDiceSides, sides)
+	}
+
+	modifier := 0
+	if match[4] != "" {
+		modifier, _ = strconv.Atoi(match[4])
+		if match[3] == "-" {
+			modifier = -modifier
+		}
+	}
+
+	roll := DiceRoll{Notation: normalized, Modifier: modifier, Total: modifier}
+	for i := 0; i < count; i++ {
+		value := rand.IntN(sides) + 1
+		roll.Rolls = append(roll.Rolls, value)
+		roll.Total += value
+	}
+	return roll, nil
+}
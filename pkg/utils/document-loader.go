@@ -0,0 +1,230 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/JCSong-89/trpg-rag-game/pkg/types"
+	"golang.org/x/net/html"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	DocumentFormatText     = "text"
+	DocumentFormatMarkdown = "markdown"
+	DocumentFormatHTML     = "html"
+	DocumentFormatJSONL    = "jsonl"
+)
+
+var documentFormats = map[string]string{
+	".txt":      DocumentFormatText,
+	".text":     DocumentFormatText,
+	".md":       DocumentFormatMarkdown,
+	".markdown": DocumentFormatMarkdown,
+	".html":     DocumentFormatHTML,
+	".htm":      DocumentFormatHTML,
+	".jsonl":    DocumentFormatJSONL,
+	".ndjson":   DocumentFormatJSONL,
+}
+
+// LoadDocuments 는 파일과 디렉터리 경로에서 지원하는 형식(txt, md, html, jsonl)의 문서를 읽습니다.
+// 디렉터리는 하위까지 훑으며 지원하지 않는 확장자는 건너뜁니다. 직접 지정한 파일의 형식이 다르면 오류입니다.
+// 결과는 경로 순서로 정렬되고, 중복 경로와 본문이 비어 있는 문서는 빠집니다.
+func LoadDocuments(paths []string) ([]types.Document, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("문서 경로 확인 실패 (%s): %w", path, err)
+		}
+		if !info.IsDir() {
+			if _, ok := documentFormats[strings.ToLower(filepath.Ext(path))]; !ok {
+				return nil, fmt.Errorf("지원하지 않는 문서 형식입니다 (%s)", path)
+			}
+			files = append(files, path)
+			continue
+		}
+
+		err = filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if _, ok := documentFormats[strings.ToLower(filepath.Ext(file))]; ok && !entry.IsDir() {
+				files = append(files, file)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("문서 디렉터리 탐색 실패 (%s): %w", path, err)
+		}
+	}
+	sort.Strings(files)
+
+	var documents []types.Document
+	for i, file := range files {
+		if i > 0 && files[i-1] == file {
+			continue
+		}
+		loaded, err := loadDocumentFile(file)
+		if err != nil {
+			return nil, err
+		}
+		documents = append(documents, loaded...)
+	}
+	return documents, nil
+}
+
+func loadDocumentFile(path string) ([]types.Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("문서 읽기 실패 (%s): %w", path, err)
+	}
+
+	format := documentFormats[strings.ToLower(filepath.Ext(path))]
+	title := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	var text string
+	switch format {
+	case DocumentFormatJSONL:
+		return parseJSONLDocuments(path, data)
+	case DocumentFormatHTML:
+		var htmlTitle string
+		text, htmlTitle = htmlToText(data)
+		if htmlTitle != "" {
+			title = htmlTitle
+		}
+	case DocumentFormatMarkdown:
+		text = string(data)
+		if heading := firstMarkdownHeading(text); heading != "" {
+			title = heading
+		}
+	default:
+		text = string(data)
+	}
+
+	document := NewDocument(path, path, format, title, text)
+	if document.Text == "" {
+		return nil, nil
+	}
+	return []types.Document{document}, nil
+}
+
+// NewDocument 는 본문 앞뒤 공백을 정리하고 Hash 를 채운 문서를 만듭니다.
+func NewDocument(id string, source string, format string, title string, text string) types.Document {
+	text = strings.TrimSpace(text)
+	sum := sha256.Sum256([]byte(text))
+	return types.Document{ID: id, Source: source, Format: format, Title: title, Text: text, Hash: hex.EncodeToString(sum[:])}
+}
+
+// parseJSONLDocuments 는 한 줄에 JSON 객체 하나씩인 파일을 줄마다 문서로 만듭니다.
+// 본문은 text, content, body 필드 중 처음 있는 것을 쓰고, id 와 title 필드가 있으면 문서 ID 와 제목으로 씁니다.
+func parseJSONLDocuments(path string, data []byte) ([]types.Document, error) {
+	var documents []types.Document
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var record map[string]any
+		if err := json.Unmarshal(line, &record); err != nil {
+			return nil, fmt.Errorf("JSON lines 파싱 실패 (%s:%d): %w", path, lineNumber, err)
+		}
+
+		var text string
+		for _, key := range []string{"text", "content", "body"} {
+			if value, ok := record[key].(string); ok && strings.TrimSpace(value) != "" {
+				text = value
+				break
+			}
+		}
+		if text == "" {
+			continue
+		}
+
+		id := fmt.Sprintf("%s#%d", path, lineNumber)
+		if value, ok := record["id"]; ok && value != nil {
+			id = fmt.Sprintf("%s#%v", path, value)
+		}
+		title, _ := record["title"].(string)
+		documents = append(documents, NewDocument(id, path, DocumentFormatJSONL, title, text))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("JSON lines 읽기 실패 (%s): %w", path, err)
+	}
+	return documents, nil
+}
+
+// htmlBlockElements 는 앞뒤로 줄을 나눠야 문장이 붙지 않는 요소입니다.
+var htmlBlockElements = map[string]bool{
+	"p": true, "div": true, "br": true, "li": true, "tr": true, "section": true, "article": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "blockquote": true, "pre": true,
+}
+
+// htmlToText 는 script, style 등을 제외한 HTML 본문 텍스트와 <title> 을 돌려줍니다.
+func htmlToText(data []byte) (string, string) {
+	tokenizer := html.NewTokenizer(bytes.NewReader(data))
+	var text, title strings.Builder
+	skipDepth := 0
+	inTitle := false
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return collapseBlankLines(text.String()), strings.TrimSpace(title.String())
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, _ := tokenizer.TagName()
+			switch tag := string(name); {
+			case tag == "script" || tag == "style" || tag == "noscript" || tag == "head":
+				skipDepth++
+			case tag == "title":
+				inTitle = true
+			case htmlBlockElements[tag]:
+				text.WriteString("\n")
+			}
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			switch tag := string(name); {
+			case tag == "script" || tag == "style" || tag == "noscript" || tag == "head":
+				skipDepth = max(skipDepth-1, 0)
+			case tag == "title":
+				inTitle = false
+			case htmlBlockElements[tag]:
+				text.WriteString("\n")
+			}
+		case html.TextToken:
+			if inTitle {
+				title.Write(tokenizer.Text())
+			} else if skipDepth == 0 {
+				text.Write(tokenizer.Text())
+			}
+		}
+	}
+}
+
+func collapseBlankLines(text string) string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.Join(strings.Fields(line), " ")
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func firstMarkdownHeading(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, "#") {
+			return strings.TrimSpace(strings.TrimLeft(trimmed, "#"))
+		}
+	}
+	return ""
+}
@@ -0,0 +1,51 @@
+package utils
+
+import "strings"
+
+const (
+	hangulSyllableFirst = 0xAC00
+	hangulSyllableLast  = 0xD7A3
+)
+
+// 국어의 로마자 표기법(2000) 자모 표입니다. 음운 변화는 반영하지 않고 글자 단위로 옮깁니다.
+var (
+	hangulInitials = []string{"g", "kk", "n", "d", "tt", "r", "m", "b", "pp", "s", "ss", "", "j", "jj", "ch", "k", "t", "p", "h"}
+	hangulMedials  = []string{"a", "ae", "ya", "yae", "eo", "e", "yeo", "ye", "o", "wa", "wae", "oe", "yo", "u", "wo", "we", "wi", "yu", "eu", "ui", "i"}
+	hangulFinals   = []string{"", "k", "k", "k", "n", "n", "n", "t", "l", "k", "m", "l", "l", "l", "p", "l", "m", "p", "p", "t", "t", "ng", "t", "t", "k", "t", "p", "t"}
+)
+
+// koreanSurnames 는 인명에서 표기법과 다르게 굳어진 성씨 표기입니다 (예: 김 → kim, 이 → lee).
+var koreanSurnames = map[rune]string{
+	'김': "kim", '이': "lee", '박': "park", '최': "choi", '정': "jung", '강': "kang", '조': "cho",
+	'윤': "yoon", '임': "lim", '오': "oh", '신': "shin", '권': "kwon", '안': "ahn", '유': "yoo",
+	'류': "ryu", '노': "noh", '우': "woo", '구': "koo", '전': "jeon", '기': "ki", '곽': "kwak",
+}
+
+// IsHangulSyllable 은 r 이 완성형 한글 음절인지 알려줍니다.
+func IsHangulSyllable(r rune) bool {
+	return r >= hangulSyllableFirst && r <= hangulSyllableLast
+}
+
+// RomanizeHangulSyllable 은 한글 음절 하나를 로마자로 옮깁니다. 한글 음절이 아니면 그대로 돌려줍니다.
+func RomanizeHangulSyllable(r rune) string {
+	if !IsHangulSyllable(r) {
+		return string(r)
+	}
+	code := int(r - hangulSyllableFirst)
+	return hangulInitials[code/588] + hangulMedials[code%588/28] + hangulFinals[code%28]
+}
+
+// RomanizeHangul 은 text 의 한글 음절을 로마자로 옮기고 나머지 글자는 그대로 둡니다. 예: "손흥민" → "sonheungmin"
+func RomanizeHangul(text string) string {
+	var sb strings.Builder
+	for _, r := range text {
+		sb.WriteString(RomanizeHangulSyllable(r))
+	}
+	return sb.String()
+}
+
+// KoreanSurnameRomanization 은 한글 인명의 첫 음절에 굳어진 성씨 표기가 있으면 그 표기를 돌려줍니다.
+func KoreanSurnameRomanization(r rune) (string, bool) {
+	surname, ok := koreanSurnames[r]
+	return surname, ok
+}
@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"fmt"
+	"github.com/JCSong-89/trpg-rag-game/pkg/types"
+	"strings"
+)
+
+// SourceChunksToString 은 출처 청크를 id, 출처 표기, 원문 순서로 나열합니다. maxRunes 가 0 보다 크면
+// 원문을 그 길이에서 자르고 "..." 를 붙입니다.
+func SourceChunksToString(chunks []types.SourceChunk, maxRunes int) string {
+	if len(chunks) == 0 {
+		return "No source excerpts found."
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Source excerpts:\n")
+	for _, chunk := range chunks {
+		text := chunk.Text
+		if runes := []rune(text); maxRunes > 0 && len(runes) > maxRunes {
+			text = string(runes[:maxRunes]) + "..."
+		}
+		sb.WriteString(fmt.Sprintf("\n[%s] %s\n%s\n", chunk.ID, chunk.Citation(), text))
+	}
+	return sb.String()
+}
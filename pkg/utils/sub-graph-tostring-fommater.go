@@ -18,7 +18,11 @@ func SubgraphToString(subgraph *types.Subgraph) string {
 		sb.WriteString(fmt.Sprintf("\n- Entity: %s (Type: %s)\n", entity.Name, entity.Label))
 		for _, relation := range subgraph.Relations {
 			if relation.SourceName == entity.Name {
-				sb.WriteString(fmt.Sprintf("  - [%s] --(%s)--> [%s]\n", relation.SourceName, relation.Type, relation.TargetName))
+				sb.WriteString(fmt.Sprintf("  - [%s] --(%s)--> [%s]", relation.SourceName, relation.Type, relation.TargetName))
+				if len(relation.Sources) > 0 {
+					sb.WriteString(fmt.Sprintf(" (sources: %s)", strings.Join(relation.Sources, ", ")))
+				}
+				sb.WriteString("\n")
 			}
 			if relation.TargetName == entity.Name {
 			}